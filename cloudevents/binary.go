@@ -0,0 +1,95 @@
+package cloudevents
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stackus/edat/msg"
+)
+
+// HeaderPrefix is the prefix of the HTTP headers holding the CloudEvents attributes in the binary mode
+const HeaderPrefix = "ce-"
+
+// WriteBinary sets the CloudEvents attributes of the message into the HTTP headers in the binary mode
+//
+// The message payload is to be used as the HTTP body
+func WriteBinary(message msg.Message, header http.Header, options ...EncodeOption) error {
+	attrs, err := attributes(message, options...)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range attrs {
+		if name == AttributeDataContentType {
+			header.Set("Content-Type", value)
+			continue
+		}
+		header.Set(HeaderPrefix+name, encodeHeaderValue(value))
+	}
+
+	return nil
+}
+
+// ReadBinary decodes the HTTP headers and body of a CloudEvent in the binary mode into a message
+func ReadBinary(header http.Header, body []byte) (msg.Message, error) {
+	attrs := make(map[string]string)
+
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, HeaderPrefix) || len(values) == 0 {
+			continue
+		}
+
+		value, err := decodeHeaderValue(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudevents header `%s`: %w", key, err)
+		}
+
+		attrs[name[len(HeaderPrefix):]] = value
+	}
+
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		attrs[AttributeDataContentType] = contentType
+	}
+
+	return newMessage(attrs, body)
+}
+
+// encodeHeaderValue percent-encodes the characters the HTTP binding does not allow in header values
+func encodeHeaderValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func decodeHeaderValue(value string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("invalid percent-encoding `%s`", value)
+		}
+		c, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid percent-encoding `%s`", value)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,195 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stackus/edat/msg"
+)
+
+// CloudEvents context attribute and extension names
+const (
+	SpecVersion = "1.0"
+
+	AttributeSpecVersion     = "specversion"
+	AttributeID              = "id"
+	AttributeSource          = "source"
+	AttributeType            = "type"
+	AttributeSubject         = "subject"
+	AttributeTime            = "time"
+	AttributeDataContentType = "datacontenttype"
+
+	ExtensionKind          = "edatkind"
+	ExtensionHeaders       = "edatheaders"
+	ExtensionCorrelationID = "correlationid"
+	ExtensionCausationID   = "causationid"
+	ExtensionTraceParent   = "traceparent"
+	ExtensionTraceState    = "tracestate"
+)
+
+// Message kinds recorded in the edatkind extension
+//
+// CloudEvents without the edatkind extension, such as those from other producers, are received as events
+const (
+	KindEvent   = "event"
+	KindCommand = "command"
+	KindReply   = "reply"
+	KindMessage = "message"
+)
+
+// DefaultSource is the source attribute used when one has not been provided
+const DefaultSource = "edat"
+
+// DefaultType is the type attribute used for messages that are not events, commands, or replies
+const DefaultType = "edat.msg.Message"
+
+// kindHeaders maps the message kinds to the header holding the name used for the type attribute
+var kindHeaders = map[string]string{
+	KindEvent:   msg.MessageEventName,
+	KindCommand: msg.MessageCommandName,
+	KindReply:   msg.MessageReplyName,
+}
+
+// extensionHeaders maps the edat headers that are carried by their own extension
+var extensionHeaders = map[string]string{
+	msg.MessageCorrelationID: ExtensionCorrelationID,
+	msg.MessageCausationID:   ExtensionCausationID,
+	msg.MessageTraceParent:   ExtensionTraceParent,
+	msg.MessageTraceState:    ExtensionTraceState,
+}
+
+// EncodeOption options for encoding messages as CloudEvents
+type EncodeOption func(*encoder)
+
+type encoder struct {
+	source string
+}
+
+// WithSource is an option to set the source attribute of the encoded CloudEvents
+func WithSource(source string) EncodeOption {
+	return func(e *encoder) {
+		e.source = source
+	}
+}
+
+// attributes returns the context attributes and extensions of the message
+//
+// Every header not mapped to an attribute or extension is kept in the edatheaders extension
+func attributes(message msg.Message, options ...EncodeOption) (map[string]string, error) {
+	e := &encoder{source: DefaultSource}
+	for _, option := range options {
+		option(e)
+	}
+
+	headers := make(msg.Headers, len(message.Headers()))
+	for key, value := range message.Headers() {
+		headers[key] = value
+	}
+
+	attrs := map[string]string{
+		AttributeSpecVersion: SpecVersion,
+		AttributeID:          message.ID(),
+		AttributeSource:      e.source,
+		AttributeType:        DefaultType,
+		ExtensionKind:        KindMessage,
+	}
+
+	if headers.Get(msg.MessageID) == message.ID() {
+		delete(headers, msg.MessageID)
+	}
+
+	for _, kind := range []string{KindEvent, KindCommand, KindReply} {
+		if name, exists := headers[kindHeaders[kind]]; exists {
+			attrs[AttributeType] = name
+			attrs[ExtensionKind] = kind
+			delete(headers, kindHeaders[kind])
+			break
+		}
+	}
+
+	if entityID, exists := headers[msg.MessageEventEntityID]; exists && entityID != "" {
+		attrs[AttributeSubject] = entityID
+		delete(headers, msg.MessageEventEntityID)
+	}
+
+	if date, exists := headers[msg.MessageDate]; exists {
+		if _, err := time.Parse(time.RFC3339, date); err == nil {
+			attrs[AttributeTime] = date
+			delete(headers, msg.MessageDate)
+		}
+	}
+
+	if contentType, exists := headers[msg.MessageContentType]; exists && contentType != "" {
+		attrs[AttributeDataContentType] = contentType
+		delete(headers, msg.MessageContentType)
+	}
+
+	for header, extension := range extensionHeaders {
+		if value, exists := headers[header]; exists && value != "" {
+			attrs[extension] = value
+			delete(headers, header)
+		}
+	}
+
+	if len(headers) > 0 {
+		data, err := json.Marshal(headers)
+		if err != nil {
+			return nil, err
+		}
+		attrs[ExtensionHeaders] = string(data)
+	}
+
+	return attrs, nil
+}
+
+// newMessage returns the message built from the context attributes, extensions and data
+func newMessage(attrs map[string]string, data []byte) (msg.Message, error) {
+	if version := attrs[AttributeSpecVersion]; version != SpecVersion {
+		return nil, fmt.Errorf("unsupported cloudevents specversion `%s`", version)
+	}
+
+	for _, required := range []string{AttributeID, AttributeSource, AttributeType} {
+		if attrs[required] == "" {
+			return nil, fmt.Errorf("missing required cloudevents attribute `%s`", required)
+		}
+	}
+
+	headers := msg.Headers{}
+
+	if value, exists := attrs[ExtensionHeaders]; exists {
+		err := json.Unmarshal([]byte(value), &headers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudevents extension `%s`: %w", ExtensionHeaders, err)
+		}
+	}
+
+	kind, exists := attrs[ExtensionKind]
+	if !exists {
+		kind = KindEvent
+	}
+
+	if header, exists := kindHeaders[kind]; exists {
+		headers[header] = attrs[AttributeType]
+	}
+
+	if value, exists := attrs[AttributeSubject]; exists {
+		headers[msg.MessageEventEntityID] = value
+	}
+
+	if value, exists := attrs[AttributeTime]; exists {
+		headers[msg.MessageDate] = value
+	}
+
+	if value, exists := attrs[AttributeDataContentType]; exists {
+		headers[msg.MessageContentType] = value
+	}
+
+	for header, extension := range extensionHeaders {
+		if value, exists := attrs[extension]; exists {
+			headers[header] = value
+		}
+	}
+
+	return msg.NewMessage(data, msg.WithMessageID(attrs[AttributeID]), msg.WithHeaders(headers)), nil
+}
@@ -0,0 +1,164 @@
+package cloudevents_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/msg"
+)
+
+func testMessages() map[string]msg.Message {
+	return map[string]msg.Message{
+		"EntityEvent": msg.NewMessage([]byte(`{"OrderID": "order-id", "Total": 100}`), msg.WithHeaders(map[string]string{
+			msg.MessageDate:            "2021-06-01T12:30:00Z",
+			msg.MessageChannel:         "orders",
+			msg.MessageCorrelationID:   "correlation-id",
+			msg.MessageCausationID:     "causation-id",
+			msg.MessageContentType:     "application/json",
+			msg.MessageTraceParent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			msg.MessageEventName:       "orders.OrderCreated",
+			msg.MessageEventEntityName: "orders.Order",
+			msg.MessageEventEntityID:   "order-id",
+		})),
+		"Command": msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{
+			msg.MessageChannel:             "payments",
+			msg.MessageCommandName:         "payments.Authorize",
+			msg.MessageCommandReplyChannel: "orders.reply",
+			"COMMAND_SAGA_ID":              "saga-id",
+		})),
+		"Reply": msg.NewMessage([]byte(`{"Value":"ok"}`), msg.WithHeaders(map[string]string{
+			msg.MessageReplyName:    "edat.msg.Success",
+			msg.MessageReplyOutcome: msg.ReplyOutcomeSuccess,
+			"REPLY_SAGA_ID":         "saga-id",
+		})),
+		"BinaryPayload": msg.NewMessage([]byte{0x00, 0xff, 0x10, '"'}, msg.WithHeaders(map[string]string{
+			msg.MessageContentType: "application/x-msgpack",
+			"CUSTOM":               "has spaces, \"quotes\", 100% and ünïcode",
+		})),
+		"TextPayload": msg.NewMessage([]byte(`plain text`), msg.WithHeaders(map[string]string{
+			msg.MessageContentType: "text/plain; charset=utf-8",
+			msg.MessageDate:        "not a date",
+		})),
+		"NoPayload": msg.NewMessage(nil),
+	}
+}
+
+func assertRoundTrip(t *testing.T, want, got msg.Message) {
+	t.Helper()
+	if got.ID() != want.ID() {
+		t.Errorf("ID() = %v, want %v", got.ID(), want.ID())
+	}
+	if !reflect.DeepEqual(got.Headers(), want.Headers()) {
+		t.Errorf("Headers() = %v, want %v", got.Headers(), want.Headers())
+	}
+	if !bytes.Equal(got.Payload(), want.Payload()) {
+		t.Errorf("Payload() = %q, want %q", got.Payload(), want.Payload())
+	}
+}
+
+func TestStructured_RoundTrip(t *testing.T) {
+	for name, message := range testMessages() {
+		t.Run(name, func(t *testing.T) {
+			data, err := cloudevents.MarshalStructured(message)
+			if err != nil {
+				t.Fatalf("MarshalStructured() error = %v", err)
+			}
+			if !json.Valid(data) {
+				t.Fatalf("MarshalStructured() produced invalid json %s", data)
+			}
+			got, err := cloudevents.UnmarshalStructured(data)
+			if err != nil {
+				t.Fatalf("UnmarshalStructured() error = %v", err)
+			}
+			assertRoundTrip(t, message, got)
+		})
+	}
+}
+
+func TestBinary_RoundTrip(t *testing.T) {
+	for name, message := range testMessages() {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			err := cloudevents.WriteBinary(message, header)
+			if err != nil {
+				t.Fatalf("WriteBinary() error = %v", err)
+			}
+			got, err := cloudevents.ReadBinary(header, message.Payload())
+			if err != nil {
+				t.Fatalf("ReadBinary() error = %v", err)
+			}
+			assertRoundTrip(t, message, got)
+		})
+	}
+}
+
+func TestMarshalStructured_Attributes(t *testing.T) {
+	message := testMessages()["EntityEvent"]
+
+	data, err := cloudevents.MarshalStructured(message, cloudevents.WithSource("/orders"))
+	if err != nil {
+		t.Fatalf("MarshalStructured() error = %v", err)
+	}
+
+	var event map[string]interface{}
+	if err = json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := map[string]interface{}{
+		cloudevents.AttributeSpecVersion:     cloudevents.SpecVersion,
+		cloudevents.AttributeID:              message.ID(),
+		cloudevents.AttributeSource:          "/orders",
+		cloudevents.AttributeType:            "orders.OrderCreated",
+		cloudevents.AttributeSubject:         "order-id",
+		cloudevents.AttributeTime:            "2021-06-01T12:30:00Z",
+		cloudevents.AttributeDataContentType: "application/json",
+		cloudevents.ExtensionKind:            cloudevents.KindEvent,
+		cloudevents.ExtensionCorrelationID:   "correlation-id",
+		cloudevents.ExtensionCausationID:     "causation-id",
+		cloudevents.ExtensionTraceParent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		cloudevents.ExtensionHeaders:         `{"CHANNEL":"orders","EVENT_ENTITY_NAME":"orders.Order"}`,
+		"data":                               map[string]interface{}{"OrderID": "order-id", "Total": float64(100)},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("MarshalStructured() = %v, want %v", event, want)
+	}
+}
+
+func TestUnmarshalStructured_ForeignEvent(t *testing.T) {
+	data := `{"specversion":"1.0","id":"event-id","source":"/shipping","type":"com.example.ShipmentSent","subject":"shipment-id","datacontenttype":"application/json","data":{"ShipmentID":"shipment-id"}}`
+
+	message, err := cloudevents.UnmarshalStructured([]byte(data))
+	if err != nil {
+		t.Fatalf("UnmarshalStructured() error = %v", err)
+	}
+
+	want := msg.NewMessage([]byte(`{"ShipmentID":"shipment-id"}`), msg.WithMessageID("event-id"), msg.WithHeaders(map[string]string{
+		msg.MessageEventName:     "com.example.ShipmentSent",
+		msg.MessageEventEntityID: "shipment-id",
+		msg.MessageContentType:   "application/json",
+	}))
+	assertRoundTrip(t, want, message)
+}
+
+func TestUnmarshalStructured_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"SpecVersion":   `{"specversion":"0.3","id":"id","source":"source","type":"type"}`,
+		"MissingID":     `{"specversion":"1.0","source":"source","type":"type"}`,
+		"MissingSource": `{"specversion":"1.0","id":"id","type":"type"}`,
+		"MissingType":   `{"specversion":"1.0","id":"id","source":"source"}`,
+		"DataBase64":    `{"specversion":"1.0","id":"id","source":"source","type":"type","data_base64":"!!"}`,
+		"Headers":       `{"specversion":"1.0","id":"id","source":"source","type":"type","edatheaders":"[]"}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cloudevents.UnmarshalStructured([]byte(data)); err == nil {
+				t.Errorf("UnmarshalStructured() error = nil, wantErr true")
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/stackus/edat/msg"
+)
+
+// StructuredContentType is the content type of CloudEvents in the structured JSON mode
+const StructuredContentType = "application/cloudevents+json"
+
+// MarshalStructured encodes the message as a CloudEvent in the structured JSON mode
+//
+// Payloads with a JSON content type, or no content type, are embedded as the data member. All other
+// payloads are base64 encoded into the data_base64 member
+func MarshalStructured(message msg.Message, options ...EncodeOption) ([]byte, error) {
+	attrs, err := attributes(message, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := message.Payload()
+	if len(payload) > 0 && !embeddable(attrs[AttributeDataContentType], payload) {
+		attrs["data_base64"] = base64.StdEncoding.EncodeToString(payload)
+	}
+
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists := attrs["data_base64"]; exists || len(payload) == 0 {
+		return data, nil
+	}
+
+	// the payload is spliced in as-is to keep it byte for byte
+	event := make([]byte, 0, len(data)+len(payload)+8)
+	event = append(event, data[:len(data)-1]...)
+	event = append(event, `,"data":`...)
+	event = append(event, payload...)
+	event = append(event, '}')
+
+	return event, nil
+}
+
+// UnmarshalStructured decodes a CloudEvent in the structured JSON mode into a message
+func UnmarshalStructured(data []byte) (msg.Message, error) {
+	var event map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string, len(event))
+	var payload []byte
+
+	for name, raw := range event {
+		switch name {
+		case "data":
+			payload = []byte(raw)
+			// string data with a non JSON content type is the payload itself
+			var s string
+			if !isJSON(contentType(event)) && json.Unmarshal(raw, &s) == nil {
+				payload = []byte(s)
+			}
+		case "data_base64":
+			var s string
+			if err = json.Unmarshal(raw, &s); err != nil {
+				return nil, fmt.Errorf("invalid cloudevents data_base64: %w", err)
+			}
+			if payload, err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, fmt.Errorf("invalid cloudevents data_base64: %w", err)
+			}
+		default:
+			var value interface{}
+			if err = json.Unmarshal(raw, &value); err != nil {
+				return nil, err
+			}
+			switch v := value.(type) {
+			case string:
+				attrs[name] = v
+			case nil:
+				// null attributes are treated as absent
+			default:
+				attrs[name] = string(raw)
+			}
+		}
+	}
+
+	return newMessage(attrs, payload)
+}
+
+func contentType(event map[string]json.RawMessage) string {
+	var s string
+	_ = json.Unmarshal(event[AttributeDataContentType], &s)
+	return s
+}
+
+func embeddable(contentType string, payload []byte) bool {
+	return isJSON(contentType) && json.Valid(payload) && len(bytes.TrimSpace(payload)) == len(payload)
+}
+
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/stackus/edat/msg"
+)
+
+// AESCodec encrypts payloads using AES-GCM
+//
+// The ID of the key used to encrypt the payload is recorded in the message headers
+type AESCodec struct {
+	keys KeyProvider
+}
+
+var _ Codec = (*AESCodec)(nil)
+
+// NewAESCodec constructs a new AESCodec
+func NewAESCodec(keys KeyProvider) *AESCodec {
+	return &AESCodec{
+		keys: keys,
+	}
+}
+
+// Name implements Codec.Name
+func (AESCodec) Name() string { return "aes-gcm" }
+
+// Encode implements Codec.Encode
+func (c AESCodec) Encode(ctx context.Context, payload []byte, headers msg.Headers) ([]byte, error) {
+	keyID, key, err := c.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := c.gcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	headers.Set(MessageCodecKeyID, keyID)
+
+	return gcm.Seal(nonce, nonce, payload, nil), nil
+}
+
+// Decode implements Codec.Decode
+func (c AESCodec) Decode(ctx context.Context, payload []byte, headers msg.Headers) ([]byte, error) {
+	keyID, err := headers.GetRequired(MessageCodecKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := c.keys.Key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := c.gcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted payload is too short")
+	}
+
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func (c AESCodec) gcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
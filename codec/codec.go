@@ -0,0 +1,22 @@
+package codec
+
+import (
+	"context"
+
+	"github.com/stackus/edat/msg"
+)
+
+// Message header keys
+const (
+	MessageCodecs     = "CODECS"
+	MessageCodecKeyID = "CODEC_KEY_ID"
+)
+
+// Codec interface for payload transformations such as compression or encryption
+//
+// Codecs may record additional information required to decode the payload into the headers
+type Codec interface {
+	Name() string
+	Encode(ctx context.Context, payload []byte, headers msg.Headers) ([]byte, error)
+	Decode(ctx context.Context, payload []byte, headers msg.Headers) ([]byte, error)
+}
@@ -0,0 +1,64 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+
+	"github.com/stackus/edat/msg"
+)
+
+// GzipCodec compresses payloads using gzip
+type GzipCodec struct {
+	level int
+}
+
+var _ Codec = (*GzipCodec)(nil)
+
+// NewGzipCodec constructs a new GzipCodec
+func NewGzipCodec(options ...GzipCodecOption) *GzipCodec {
+	c := &GzipCodec{
+		level: gzip.DefaultCompression,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// Name implements Codec.Name
+func (GzipCodec) Name() string { return "gzip" }
+
+// Encode implements Codec.Encode
+func (c GzipCodec) Encode(_ context.Context, payload []byte, _ msg.Headers) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(payload); err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec.Decode
+func (GzipCodec) Decode(_ context.Context, payload []byte, _ msg.Headers) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,11 @@
+package codec
+
+// GzipCodecOption options for GzipCodec
+type GzipCodecOption func(*GzipCodec)
+
+// WithGzipCodecLevel sets the compression level for GzipCodec
+func WithGzipCodecLevel(level int) GzipCodecOption {
+	return func(codec *GzipCodec) {
+		codec.level = level
+	}
+}
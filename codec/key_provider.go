@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+)
+
+// KeyProvider interface for locating encryption keys
+//
+// CurrentKey returns the key that should be used to encrypt new payloads. Key returns any key, current or
+// retired, that was used to encrypt existing payloads.
+type KeyProvider interface {
+	CurrentKey(ctx context.Context) (keyID string, key []byte, err error)
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider with a fixed set of keys
+type StaticKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+var _ KeyProvider = (*StaticKeyProvider)(nil)
+
+// NewStaticKeyProvider constructs a new StaticKeyProvider
+func NewStaticKeyProvider(currentKeyID string, keys map[string][]byte) *StaticKeyProvider {
+	return &StaticKeyProvider{
+		currentKeyID: currentKeyID,
+		keys:         keys,
+	}
+}
+
+// CurrentKey implements KeyProvider.CurrentKey
+func (p StaticKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := p.Key(ctx, p.currentKeyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.currentKeyID, key, nil
+}
+
+// Key implements KeyProvider.Key
+func (p StaticKeyProvider) Key(_ context.Context, keyID string) ([]byte, error) {
+	key, exists := p.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("encryption key `%s` does not exist", keyID)
+	}
+
+	return key, nil
+}
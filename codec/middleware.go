@@ -0,0 +1,97 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stackus/edat/msg"
+)
+
+const codecSeparator = ","
+
+// PublisherMiddleware returns a msg.Publisher middleware that encodes outgoing payloads
+//
+// Codecs are applied in the order they are provided and their names are recorded in the message headers
+//  publisher.Use(codec.PublisherMiddleware(codec.NewGzipCodec(), codec.NewAESCodec(keys)))
+func PublisherMiddleware(codecs ...Codec) func(msg.MessagePublisher) msg.MessagePublisher {
+	return func(next msg.MessagePublisher) msg.MessagePublisher {
+		return msg.PublishMessageFunc(func(ctx context.Context, message msg.Message) error {
+			if len(codecs) == 0 {
+				return next.Publish(ctx, message)
+			}
+
+			headers := copyHeaders(message.Headers())
+			payload := message.Payload()
+
+			var applied []string
+			if names := headers.Get(MessageCodecs); names != "" {
+				applied = strings.Split(names, codecSeparator)
+			}
+
+			for _, codec := range codecs {
+				var err error
+				payload, err = codec.Encode(ctx, payload, headers)
+				if err != nil {
+					return fmt.Errorf("error encoding message payload with `%s`: %w", codec.Name(), err)
+				}
+				applied = append(applied, codec.Name())
+			}
+
+			headers.Set(MessageCodecs, strings.Join(applied, codecSeparator))
+
+			return next.Publish(ctx, msg.NewMessage(payload, msg.WithMessageID(message.ID()), msg.WithHeaders(headers)))
+		})
+	}
+}
+
+// SubscriberMiddleware returns a msg.Subscriber middleware that decodes incoming payloads
+//
+// Codecs recorded in the message headers are reversed in the opposite order they were applied. Messages
+// without any recorded codecs are passed along untouched.
+func SubscriberMiddleware(codecs ...Codec) func(msg.MessageReceiver) msg.MessageReceiver {
+	registered := make(map[string]Codec, len(codecs))
+	for _, codec := range codecs {
+		registered[codec.Name()] = codec
+	}
+
+	return func(next msg.MessageReceiver) msg.MessageReceiver {
+		return msg.ReceiveMessageFunc(func(ctx context.Context, message msg.Message) error {
+			names := message.Headers().Get(MessageCodecs)
+			if names == "" {
+				return next.ReceiveMessage(ctx, message)
+			}
+
+			headers := copyHeaders(message.Headers())
+			payload := message.Payload()
+
+			applied := strings.Split(names, codecSeparator)
+			for i := len(applied) - 1; i >= 0; i-- {
+				codec, exists := registered[applied[i]]
+				if !exists {
+					return fmt.Errorf("message payload was encoded with the unregistered codec `%s`", applied[i])
+				}
+
+				var err error
+				payload, err = codec.Decode(ctx, payload, headers)
+				if err != nil {
+					return fmt.Errorf("error decoding message payload with `%s`: %w", codec.Name(), err)
+				}
+			}
+
+			delete(headers, MessageCodecs)
+			delete(headers, MessageCodecKeyID)
+
+			return next.ReceiveMessage(ctx, msg.NewMessage(payload, msg.WithMessageID(message.ID()), msg.WithHeaders(headers)))
+		})
+	}
+}
+
+func copyHeaders(headers msg.Headers) msg.Headers {
+	c := make(msg.Headers, len(headers))
+	for key, value := range headers {
+		c[key] = value
+	}
+
+	return c
+}
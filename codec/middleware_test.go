@@ -0,0 +1,189 @@
+package codec_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/codec"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+)
+
+func TestMiddleware(t *testing.T) {
+	keys := codec.NewStaticKeyProvider("key-2", map[string][]byte{
+		"key-1": []byte("0123456789abcdef0123456789abcdef"),
+		"key-2": []byte("fedcba9876543210fedcba9876543210"),
+	})
+	otherKeys := codec.NewStaticKeyProvider("key-1", map[string][]byte{
+		"key-1": []byte("abcdefabcdefabcdefabcdefabcdefab"),
+	})
+
+	payload := bytes.Repeat([]byte(`{"Value":"payload"}`), 10)
+
+	tests := map[string]struct {
+		encoders    []codec.Codec
+		decoders    []codec.Codec
+		wantCodecs  string
+		wantEncoded bool
+		wantErr     bool
+	}{
+		"Passthrough": {
+			encoders:    []codec.Codec{},
+			decoders:    []codec.Codec{codec.NewGzipCodec()},
+			wantCodecs:  "",
+			wantEncoded: false,
+			wantErr:     false,
+		},
+		"Gzip": {
+			encoders:    []codec.Codec{codec.NewGzipCodec()},
+			decoders:    []codec.Codec{codec.NewGzipCodec()},
+			wantCodecs:  "gzip",
+			wantEncoded: true,
+			wantErr:     false,
+		},
+		"AES": {
+			encoders:    []codec.Codec{codec.NewAESCodec(keys)},
+			decoders:    []codec.Codec{codec.NewAESCodec(keys)},
+			wantCodecs:  "aes-gcm",
+			wantEncoded: true,
+			wantErr:     false,
+		},
+		"GzipAndAES": {
+			encoders:    []codec.Codec{codec.NewGzipCodec(), codec.NewAESCodec(keys)},
+			decoders:    []codec.Codec{codec.NewAESCodec(keys), codec.NewGzipCodec()},
+			wantCodecs:  "gzip,aes-gcm",
+			wantEncoded: true,
+			wantErr:     false,
+		},
+		"UnregisteredCodec": {
+			encoders:    []codec.Codec{codec.NewGzipCodec()},
+			decoders:    []codec.Codec{},
+			wantCodecs:  "gzip",
+			wantEncoded: true,
+			wantErr:     true,
+		},
+		"UnknownKey": {
+			encoders:    []codec.Codec{codec.NewAESCodec(keys)},
+			decoders:    []codec.Codec{codec.NewAESCodec(otherKeys)},
+			wantCodecs:  "aes-gcm",
+			wantEncoded: true,
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var published msg.Message
+			publisher := codec.PublisherMiddleware(tt.encoders...)(msg.PublishMessageFunc(func(_ context.Context, message msg.Message) error {
+				published = message
+				return nil
+			}))
+
+			var received msg.Message
+			receiver := codec.SubscriberMiddleware(tt.decoders...)(msg.ReceiveMessageFunc(func(_ context.Context, message msg.Message) error {
+				received = message
+				return nil
+			}))
+
+			original := msg.NewMessage(payload, msg.WithHeaders(map[string]string{"header": "value"}))
+
+			if err := publisher.Publish(context.Background(), original); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			if got := published.Headers().Get(codec.MessageCodecs); got != tt.wantCodecs {
+				t.Errorf("Publish() codecs = %v, want %v", got, tt.wantCodecs)
+			}
+			if encoded := !bytes.Equal(published.Payload(), payload); encoded != tt.wantEncoded {
+				t.Errorf("Publish() encoded = %v, want %v", encoded, tt.wantEncoded)
+			}
+			if published.ID() != original.ID() {
+				t.Errorf("Publish() id = %v, want %v", published.ID(), original.ID())
+			}
+
+			err := receiver.ReceiveMessage(context.Background(), published)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReceiveMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !bytes.Equal(received.Payload(), payload) {
+				t.Errorf("ReceiveMessage() payload = %s, want %s", received.Payload(), payload)
+			}
+			if received.Headers().Has(codec.MessageCodecs) || received.Headers().Has(codec.MessageCodecKeyID) {
+				t.Errorf("ReceiveMessage() codec headers were not removed: %v", received.Headers())
+			}
+			if received.Headers().Get("header") != "value" {
+				t.Errorf("ReceiveMessage() headers = %v, want header to be preserved", received.Headers())
+			}
+		})
+	}
+}
+
+type recordingProducer struct {
+	messages []msg.Message
+	mu       sync.Mutex
+}
+
+func (p *recordingProducer) Send(_ context.Context, _ string, message msg.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func (p *recordingProducer) Close(context.Context) error { return nil }
+
+func (p *recordingProducer) sent() []msg.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]msg.Message{}, p.messages...)
+}
+
+func TestMiddleware_Scheduler(t *testing.T) {
+	payload := bytes.Repeat([]byte(`{"Value":"payload"}`), 10)
+
+	producer := &recordingProducer{}
+	publisher := msg.NewPublisher(producer)
+	scheduler := msg.NewScheduler(inmem.NewScheduledMessageStore(), publisher, msg.WithSchedulerPollingInterval(time.Millisecond))
+	publisher.Use(codec.PublisherMiddleware(codec.NewGzipCodec()), scheduler.PublisherMiddleware)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() { _ = scheduler.Start(ctx) }()
+	defer func() { _ = scheduler.Stop(ctx) }()
+
+	message := msg.NewMessage(payload, msg.WithDestinationChannel("channel"), msg.WithDelay(10*time.Millisecond))
+	if err := publisher.Publish(ctx, message); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	for len(producer.sent()) == 0 && ctx.Err() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	sent := producer.sent()
+	if len(sent) != 1 {
+		t.Fatalf("messages sent = %d, want 1", len(sent))
+	}
+
+	// the released message is not encoded a second time
+	if got := sent[0].Headers().Get(codec.MessageCodecs); got != "gzip" {
+		t.Errorf("released codecs = %v, want gzip", got)
+	}
+
+	var received msg.Message
+	receiver := codec.SubscriberMiddleware(codec.NewGzipCodec())(msg.ReceiveMessageFunc(func(_ context.Context, message msg.Message) error {
+		received = message
+		return nil
+	}))
+	if err := receiver.ReceiveMessage(ctx, sent[0]); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if !bytes.Equal(received.Payload(), payload) {
+		t.Errorf("ReceiveMessage() payload = %s, want %s", received.Payload(), payload)
+	}
+}
@@ -16,8 +16,15 @@ func SerializeCommand(v Command) ([]byte, error) {
 }
 
 // DeserializeCommand deserializes the command data using a registered marshaller returning a *Command
-func DeserializeCommand(commandName string, data []byte) (Command, error) {
-	cmd, err := unmarshal(commandName, data)
+//
+// The marshaller registered for the optional content type will be used when one exists
+func DeserializeCommand(commandName string, data []byte, contentType ...string) (Command, error) {
+	var ct string
+	if len(contentType) > 0 {
+		ct = contentType[0]
+	}
+
+	cmd, err := unmarshal(commandName, data, ct)
 	if err != nil {
 		return nil, err
 	}
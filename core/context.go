@@ -11,6 +11,7 @@ const (
 	requestIDKey contextKey = iota + 1
 	correlationIDKey
 	causationIDKey
+	spanContextKey
 )
 
 // GetRequestID returns the RequestID from the context or a blank if not set
@@ -0,0 +1,111 @@
+package coretest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stackus/edat/core"
+)
+
+// RecordingTracer returns a Tracer for testing purposes
+//
+// Every started span is recorded and may be inspected using Spans(). The recorded spans may be reset
+// using Reset() at any time while testing.
+type RecordingTracer struct {
+	spans []*RecordedSpan
+	mu    sync.Mutex
+}
+
+// RecordedSpan is a span that has been started by the RecordingTracer
+type RecordedSpan struct {
+	Name       string
+	Kind       core.SpanKind
+	Parent     core.SpanContext
+	Context    core.SpanContext
+	Attributes map[string]string
+	Errors     []error
+	Ended      bool
+	mu         sync.Mutex
+}
+
+var _ core.Tracer = (*RecordingTracer)(nil)
+var _ core.Span = (*RecordedSpan)(nil)
+
+// NewRecordingTracer constructs a new RecordingTracer
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// Start implements core.Tracer.Start
+func (t *RecordingTracer) Start(ctx context.Context, name string, kind core.SpanKind) (context.Context, core.Span) {
+	parent := core.GetSpanContext(ctx)
+
+	sc := core.SpanContext{
+		TraceID:    parent.TraceID,
+		SpanID:     core.NewSpanID(),
+		TraceFlags: core.TraceFlagsSampled,
+		TraceState: parent.TraceState,
+	}
+	if !parent.IsValid() {
+		sc.TraceID = core.NewTraceID()
+	}
+
+	span := &RecordedSpan{
+		Name:       name,
+		Kind:       kind,
+		Parent:     parent,
+		Context:    sc,
+		Attributes: map[string]string{},
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return core.ContextWithSpanContext(ctx, sc), span
+}
+
+// Spans returns the spans that have been started in the order they were started
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*RecordedSpan{}, t.spans...)
+}
+
+// Reset removes all of the recorded spans
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.spans = nil
+}
+
+// SpanContext implements core.Span.SpanContext
+func (s *RecordedSpan) SpanContext() core.SpanContext {
+	return s.Context
+}
+
+// SetAttribute implements core.Span.SetAttribute
+func (s *RecordedSpan) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+// RecordError implements core.Span.RecordError
+func (s *RecordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+// End implements core.Span.End
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Ended = true
+}
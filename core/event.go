@@ -16,8 +16,15 @@ func SerializeEvent(v Event) ([]byte, error) {
 }
 
 // DeserializeEvent deserializes the event data using a registered marshaller returning an *Event
-func DeserializeEvent(eventName string, data []byte) (Event, error) {
-	evt, err := unmarshal(eventName, data)
+//
+// The marshaller registered for the optional content type will be used when one exists
+func DeserializeEvent(eventName string, data []byte, contentType ...string) (Event, error) {
+	var ct string
+	if len(contentType) > 0 {
+		ct = contentType[0]
+	}
+
+	evt, err := unmarshal(eventName, data, ct)
 	if err != nil {
 		return nil, err
 	}
@@ -44,16 +44,26 @@ type registeredMarshaller struct {
 	affinity   func(interface{}) bool
 }
 
+type registeredContentType struct {
+	contentType string
+	marshaller  Marshaller
+}
+
 var registry = struct {
 	defaultMarshaller Marshaller
 	marshallers       []registeredMarshaller
-	mu                sync.Mutex
+	contentTypes      []registeredContentType
+	mu                sync.RWMutex
 }{
-	marshallers: []registeredMarshaller{},
-	mu:          sync.Mutex{},
+	marshallers:  []registeredMarshaller{},
+	contentTypes: []registeredContentType{},
+	mu:           sync.RWMutex{},
 }
 
 func registerType(typeName string, v interface{}) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
 	marshaller := registry.defaultMarshaller
 
 	for _, s := range registry.marshallers {
@@ -82,16 +92,16 @@ func registerType(typeName string, v interface{}) {
 	marshaller.RegisterType(typeName, t)
 }
 
-func marshal(typeName string, v interface{}) ([]byte, error) {
+// lookup must be called with the registry lock held
+func lookup(typeName string) (Marshaller, reflect.Type) {
 	var t reflect.Type
 
 	marshaller := registry.defaultMarshaller
-
 	if marshaller != nil {
 		t = marshaller.GetType(typeName)
 	}
 
-	if marshaller == nil || marshaller.GetType(typeName) == nil {
+	if t == nil {
 		for _, s := range registry.marshallers {
 			if t = s.marshaller.GetType(typeName); t != nil {
 				marshaller = s.marshaller
@@ -100,6 +110,14 @@ func marshal(typeName string, v interface{}) ([]byte, error) {
 		}
 	}
 
+	return marshaller, t
+}
+
+func marshal(typeName string, v interface{}) ([]byte, error) {
+	registry.mu.RLock()
+	marshaller, t := lookup(typeName)
+	registry.mu.RUnlock()
+
 	if marshaller == nil || t == nil {
 		return nil, fmt.Errorf("`%s` was not registered with any marshaller", typeName)
 	}
@@ -107,34 +125,65 @@ func marshal(typeName string, v interface{}) ([]byte, error) {
 	return marshaller.Marshal(v)
 }
 
-func unmarshal(typeName string, data []byte) (interface{}, error) {
-	var t reflect.Type
-
-	marshaller := registry.defaultMarshaller
-
-	if marshaller != nil {
-		t = marshaller.GetType(typeName)
-	}
+func unmarshal(typeName string, data []byte, contentType string) (interface{}, error) {
+	registry.mu.RLock()
+	marshaller, t := lookup(typeName)
 
-	if t == nil {
-		for _, s := range registry.marshallers {
-			if t = s.marshaller.GetType(typeName); t != nil {
-				marshaller = s.marshaller
-				break
-			}
+	// data with a known content type is given to the marshaller registered for it
+	if m := contentTypeMarshaller(contentType); m != nil {
+		marshaller = m
+		if mt := m.GetType(typeName); mt != nil {
+			t = mt
 		}
 	}
+	registry.mu.RUnlock()
 
 	if marshaller == nil || t == nil {
 		return nil, fmt.Errorf("`%s` was not registered with any marshaller", typeName)
 	}
 
 	dst := reflect.New(t).Interface()
-
 	err := marshaller.Unmarshal(data, dst)
+
 	return dst, err
 }
 
+// contentTypeMarshaller must be called with the registry lock held
+func contentTypeMarshaller(contentType string) Marshaller {
+	if contentType == "" {
+		return nil
+	}
+
+	for _, ct := range registry.contentTypes {
+		if ct.contentType == contentType {
+			return ct.marshaller
+		}
+	}
+
+	return nil
+}
+
+// GetContentType returns the content type of the marshaller used to marshal the type or a blank if it is not known
+//
+// When a marshaller has been registered under more than one content type the first one registered is returned
+func GetContentType(typeName string) string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	marshaller, t := lookup(typeName)
+	if marshaller == nil || t == nil {
+		return ""
+	}
+
+	for _, ct := range registry.contentTypes {
+		if ct.marshaller == marshaller {
+			return ct.contentType
+		}
+	}
+
+	return ""
+}
+
 // RegisterMarshaller allows applications to register a new optimized marshaller for specific types or situations
 func RegisterMarshaller(marshaller Marshaller, affinityFn func(interface{}) bool) {
 	registerMarshaller(marshaller, affinityFn, false)
@@ -145,6 +194,31 @@ func RegisterDefaultMarshaller(marshaller Marshaller) {
 	registerMarshaller(marshaller, nil, true)
 }
 
+// RegisterContentType registers the content type of the data produced by a marshaller
+//
+// Data deserialized along with a registered content type will use the marshaller registered for it instead of
+// the marshaller the type was registered with. This allows the data from other versions of an application using
+// a different marshaller to continue to be consumed. Registering a content type again replaces its marshaller.
+//  core.RegisterDefaultMarshaller(msgpack.NewMarshaller())
+//  core.RegisterContentType("application/msgpack", msgpackMarshaller)
+//  core.RegisterContentType("application/json", jsonMarshaller)
+func RegisterContentType(contentType string, marshaller Marshaller) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for i, ct := range registry.contentTypes {
+		if ct.contentType == contentType {
+			registry.contentTypes[i].marshaller = marshaller
+			return
+		}
+	}
+
+	registry.contentTypes = append(registry.contentTypes, registeredContentType{
+		contentType: contentType,
+		marshaller:  marshaller,
+	})
+}
+
 func registerMarshaller(marshaller Marshaller, affinityFn func(interface{}) bool, asDefault bool) {
 	registry.mu.Lock()
 	defer registry.mu.Unlock()
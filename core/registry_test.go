@@ -0,0 +1,124 @@
+package core_test
+
+import (
+	"encoding/xml"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+)
+
+type xmlMarshaller struct {
+	types map[string]reflect.Type
+	mu    sync.Mutex
+}
+
+func (*xmlMarshaller) Marshal(v interface{}) ([]byte, error)      { return xml.Marshal(v) }
+func (*xmlMarshaller) Unmarshal(data []byte, v interface{}) error { return xml.Unmarshal(data, v) }
+func (m *xmlMarshaller) GetType(typeName string) reflect.Type     { return m.types[typeName] }
+func (m *xmlMarshaller) RegisterType(typeName string, v reflect.Type) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.types[typeName] = v
+}
+
+func TestDeserializeEvent_ContentType(t *testing.T) {
+	type args struct {
+		eventName   string
+		data        []byte
+		contentType string
+	}
+
+	jsonMarshaller := coretest.NewTestMarshaller()
+	core.RegisterContentType("application/json", jsonMarshaller)
+	core.RegisterContentType("application/xml", &xmlMarshaller{types: map[string]reflect.Type{}})
+	core.RegisterDefaultMarshaller(&xmlMarshaller{types: map[string]reflect.Type{}})
+	t.Cleanup(func() { core.RegisterDefaultMarshaller(coretest.NewTestMarshaller()) })
+	core.RegisterEvents(testEvent{})
+
+	tests := map[string]struct {
+		args    args
+		want    core.Event
+		wantErr bool
+	}{
+		"DefaultMarshaller": {
+			args: args{
+				eventName:   testEvent{}.EventName(),
+				data:        []byte(`<testEvent><Value>event</Value></testEvent>`),
+				contentType: "",
+			},
+			want:    testEvt,
+			wantErr: false,
+		},
+		"ContentTypeMarshaller": {
+			args: args{
+				eventName:   testEvent{}.EventName(),
+				data:        getGoldenFileData(t, testEvent{}.EventName()),
+				contentType: "application/json",
+			},
+			want:    testEvt,
+			wantErr: false,
+		},
+		"UnknownContentType": {
+			args: args{
+				eventName:   testEvent{}.EventName(),
+				data:        []byte(`<testEvent><Value>event</Value></testEvent>`),
+				contentType: "application/unknown",
+			},
+			want:    testEvt,
+			wantErr: false,
+		},
+		"WrongContentType": {
+			args: args{
+				eventName:   testEvent{}.EventName(),
+				data:        getGoldenFileData(t, testEvent{}.EventName()),
+				contentType: "application/xml",
+			},
+			want:    &testEvent{},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := core.DeserializeEvent(tt.args.eventName, tt.args.data, tt.args.contentType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeserializeEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeserializeEvent() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContentType(t *testing.T) {
+	jsonMarshaller := coretest.NewTestMarshaller()
+	core.RegisterDefaultMarshaller(jsonMarshaller)
+	core.RegisterContentType("application/json", jsonMarshaller)
+	core.RegisterContentType("text/json", jsonMarshaller)
+	core.RegisterEvents(testEvent{})
+
+	tests := map[string]struct {
+		typeName string
+		want     string
+	}{
+		"Registered": {
+			typeName: testEvent{}.EventName(),
+			want:     "application/json",
+		},
+		"Unregistered": {
+			typeName: unregisteredEvent{}.EventName(),
+			want:     "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := core.GetContentType(tt.typeName); got != tt.want {
+				t.Errorf("GetContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
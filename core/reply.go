@@ -16,8 +16,15 @@ func SerializeReply(v Reply) ([]byte, error) {
 }
 
 // DeserializeReply deserializes the reply data using a registered marshaller returning a *Reply
-func DeserializeReply(replyName string, data []byte) (Reply, error) {
-	reply, err := unmarshal(replyName, data)
+//
+// The marshaller registered for the optional content type will be used when one exists
+func DeserializeReply(replyName string, data []byte, contentType ...string) (Reply, error) {
+	var ct string
+	if len(contentType) > 0 {
+		ct = contentType[0]
+	}
+
+	reply, err := unmarshal(replyName, data, ct)
 	if err != nil {
 		return nil, err
 	}
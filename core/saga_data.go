@@ -17,7 +17,7 @@ func SerializeSagaData(v SagaData) ([]byte, error) {
 
 // DeserializeSagaData deserializes the saga data data using a registered marshaller returning a *SagaData
 func DeserializeSagaData(sagaDataName string, data []byte) (SagaData, error) {
-	sagaData, err := unmarshal(sagaDataName, data)
+	sagaData, err := unmarshal(sagaDataName, data, "")
 	if err != nil {
 		return nil, err
 	}
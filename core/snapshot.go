@@ -17,7 +17,7 @@ func SerializeSnapshot(v Snapshot) ([]byte, error) {
 
 // DeserializeSnapshot deserializes the snapshot data using a registered marshaller returning a *Snapshot
 func DeserializeSnapshot(snapshotName string, data []byte) (Snapshot, error) {
-	snapshot, err := unmarshal(snapshotName, data)
+	snapshot, err := unmarshal(snapshotName, data, "")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// W3C trace context header keys
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// SpanKind describes the relationship of a span to its parent
+type SpanKind int
+
+// Span kinds
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// TraceFlagsSampled is set in SpanContext.TraceFlags when the trace has been sampled
+const TraceFlagsSampled byte = 0x01
+
+// SpanContext is the W3C trace context of a span
+type SpanContext struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags byte
+	TraceState string
+}
+
+// Span is a single unit of work within a trace
+type Span interface {
+	SpanContext() SpanContext
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// Tracer interface
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// DefaultTracer is set to a Nop tracer
+//
+// The Nop tracer does not record spans but will still propagate any incoming trace context. You may
+// reassign this to integrate with your tracing system
+var DefaultTracer Tracer = NewNopTracer()
+
+// IsValid returns whether or not the trace and span ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+// TraceParent returns the W3C traceparent value for the span context
+func (sc SpanContext) TraceParent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.TraceFlags)
+}
+
+// ParseTraceParent returns the span context of the W3C traceparent and tracestate values
+func ParseTraceParent(traceParent, traceState string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, fmt.Errorf("invalid traceparent `%s`", traceParent)
+	}
+
+	// only version 00 has a fixed number of fields
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent `%s`", traceParent)
+	}
+
+	traceID, spanID := parts[1], parts[2]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent `%s`", traceParent)
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent `%s`", traceParent)
+	}
+
+	return SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags[0],
+		TraceState: traceState,
+	}, nil
+}
+
+// ContextWithSpanContext returns a context with the span context set as the current span
+//
+// This is used to continue a trace that has been received from another service
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey, sc)
+}
+
+// GetSpanContext returns the span context of the current span or an empty SpanContext if not set
+func GetSpanContext(ctx context.Context) SpanContext {
+	sc := ctx.Value(spanContextKey)
+	if sc == nil {
+		return SpanContext{}
+	}
+
+	return sc.(SpanContext)
+}
+
+// ContextWithTraceParent returns a context continuing the trace of the W3C traceparent and tracestate values
+//
+// The context is returned unchanged when the traceparent is blank or invalid
+func ContextWithTraceParent(ctx context.Context, traceParent, traceState string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+
+	sc, err := ParseTraceParent(traceParent, traceState)
+	if err != nil {
+		return ctx
+	}
+
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// NewTraceID returns a new random W3C trace id
+func NewTraceID() string {
+	return randomHexID(16)
+}
+
+// NewSpanID returns a new random W3C span id
+func NewSpanID() string {
+	return randomHexID(8)
+}
+
+func randomHexID(size int) string {
+	b := make([]byte, size)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+
+	_, err := hex.DecodeString(id)
+
+	return err == nil && strings.ToLower(id) == id
+}
+
+type nopTracer struct{}
+
+type nopSpan struct {
+	sc SpanContext
+}
+
+// NewNopTracer returns a Tracer that does not record any spans
+func NewNopTracer() Tracer {
+	return nopTracer{}
+}
+
+func (nopTracer) Start(ctx context.Context, _ string, _ SpanKind) (context.Context, Span) {
+	return ctx, nopSpan{GetSpanContext(ctx)}
+}
+
+func (s nopSpan) SpanContext() SpanContext  { return s.sc }
+func (nopSpan) SetAttribute(string, string) {}
+func (nopSpan) RecordError(error)           {}
+func (nopSpan) End()                        {}
@@ -0,0 +1,129 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	type args struct {
+		traceParent string
+		traceState  string
+	}
+	tests := map[string]struct {
+		args    args
+		want    core.SpanContext
+		wantErr bool
+	}{
+		"Sampled": {
+			args: args{
+				traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				traceState:  "vendor=value",
+			},
+			want: core.SpanContext{
+				TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:     "00f067aa0ba902b7",
+				TraceFlags: core.TraceFlagsSampled,
+				TraceState: "vendor=value",
+			},
+			wantErr: false,
+		},
+		"NotSampled": {
+			args: args{
+				traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			},
+			want: core.SpanContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			},
+			wantErr: false,
+		},
+		"FutureVersion": {
+			args: args{
+				traceParent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			},
+			want: core.SpanContext{
+				TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:     "00f067aa0ba902b7",
+				TraceFlags: core.TraceFlagsSampled,
+			},
+			wantErr: false,
+		},
+		"InvalidVersion": {
+			args: args{
+				traceParent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			wantErr: true,
+		},
+		"ZeroTraceID": {
+			args: args{
+				traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			},
+			wantErr: true,
+		},
+		"ShortSpanID": {
+			args: args{
+				traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01",
+			},
+			wantErr: true,
+		},
+		"Blank": {
+			args:    args{},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := core.ParseTraceParent(tt.args.traceParent, tt.args.traceState)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTraceParent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseTraceParent() got = %v, want %v", got, tt.want)
+			}
+			if err == nil && tt.args.traceParent[:2] == "00" && got.TraceParent() != tt.args.traceParent {
+				t.Errorf("TraceParent() got = %v, want %v", got.TraceParent(), tt.args.traceParent)
+			}
+		})
+	}
+}
+
+func TestNopTracer_Start(t *testing.T) {
+	parent := core.SpanContext{TraceID: core.NewTraceID(), SpanID: core.NewSpanID()}
+	ctx := core.ContextWithSpanContext(context.Background(), parent)
+
+	_, span := core.NewNopTracer().Start(ctx, "span", core.SpanKindInternal)
+	if span.SpanContext() != parent {
+		t.Errorf("SpanContext() got = %v, want %v", span.SpanContext(), parent)
+	}
+}
+
+func TestRecordingTracer_Start(t *testing.T) {
+	tracer := coretest.NewRecordingTracer()
+
+	ctx, root := tracer.Start(context.Background(), "root", core.SpanKindServer)
+	_, child := tracer.Start(ctx, "child", core.SpanKindProducer)
+	child.End()
+	root.End()
+
+	spans := tracer.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Spans() got = %d spans, want 2", len(spans))
+	}
+	if spans[0].Parent.IsValid() {
+		t.Errorf("root span has a parent `%v`", spans[0].Parent)
+	}
+	if spans[1].Parent != root.SpanContext() {
+		t.Errorf("child span parent got = %v, want %v", spans[1].Parent, root.SpanContext())
+	}
+	if child.SpanContext().TraceID != root.SpanContext().TraceID {
+		t.Errorf("child span trace id got = %v, want %v", child.SpanContext().TraceID, root.SpanContext().TraceID)
+	}
+	if !spans[0].Ended || !spans[1].Ended {
+		t.Errorf("spans have not been ended")
+	}
+}
@@ -3,8 +3,10 @@ module github.com/stackus/edat
 go 1.16
 
 require (
+	github.com/golang/protobuf v1.4.2
 	github.com/google/uuid v1.1.4
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/sync v0.0.0-20201207232520-09787c993a3a
 	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.25.0
 )
@@ -0,0 +1,56 @@
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative brokerpb/broker.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stackus/edat/grpc/brokerpb"
+)
+
+// BrokerServiceName is the full name of the broker service defined in brokerpb/broker.proto
+const BrokerServiceName = "edat.broker.Broker"
+
+// publishMethod is the full method name of the broker Publish RPC
+const publishMethod = "/" + BrokerServiceName + "/Publish"
+
+// Broker metadata
+const (
+	channelKey    = "edat-channel"
+	listenerIDKey = "edat-listener-id"
+)
+
+// brokerService adapts a Broker to the generated brokerpb.BrokerServer
+type brokerService struct {
+	brokerpb.UnimplementedBrokerServer
+	broker *Broker
+}
+
+var _ brokerpb.BrokerServer = (*brokerService)(nil)
+
+// RegisterBrokerServer registers the broker service with a grpc.Server
+//  server := grpc.NewServer()
+//  edatgrpc.RegisterBrokerServer(server, edatgrpc.NewBroker())
+func RegisterBrokerServer(registrar grpc.ServiceRegistrar, broker *Broker) {
+	brokerpb.RegisterBrokerServer(registrar, &brokerService{broker: broker})
+}
+
+func (s *brokerService) Publish(ctx context.Context, event *wrapperspb.BytesValue) (*emptypb.Empty, error) {
+	return s.broker.publish(ctx, event)
+}
+
+func (s *brokerService) Listen(channel *wrapperspb.StringValue, stream brokerpb.Broker_ListenServer) error {
+	return s.broker.listen(channel, stream)
+}
+
+func (s *brokerService) Ack(ctx context.Context, messageID *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return s.broker.ack(ctx, messageID)
+}
+
+func (s *brokerService) Nack(ctx context.Context, messageID *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return s.broker.nack(ctx, messageID)
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/retry"
+)
+
+// BrokerOption options for Broker
+type BrokerOption func(*Broker)
+
+// WithBrokerChannelBufferSize sets the number of messages each channel will hold before publishing blocks
+func WithBrokerChannelBufferSize(channelBufferSize int) BrokerOption {
+	return func(broker *Broker) {
+		broker.channelBufferSize = channelBufferSize
+	}
+}
+
+// WithBrokerAckTimeout sets how long the Broker waits for a listener to acknowledge a message
+//
+// Listeners that do not acknowledge a message in time are disconnected and the message is delivered again
+func WithBrokerAckTimeout(ackTimeout time.Duration) BrokerOption {
+	return func(broker *Broker) {
+		broker.ackTimeout = ackTimeout
+	}
+}
+
+// WithBrokerMaxDeliveries sets how many times a message is delivered before it is dead lettered
+//
+// A value of zero delivers messages until they have been acknowledged
+func WithBrokerMaxDeliveries(maxDeliveries int) BrokerOption {
+	return func(broker *Broker) {
+		broker.maxDeliveries = maxDeliveries
+	}
+}
+
+// WithBrokerRedeliveryBackoff sets the retry.Backoff used to delay the redelivery of rejected messages
+//
+// The interval is chosen by the number of times the message has been delivered; the maximum retries of the backoff
+// are not used
+func WithBrokerRedeliveryBackoff(backoff *retry.Backoff) BrokerOption {
+	return func(broker *Broker) {
+		broker.redelivery = backoff
+	}
+}
+
+// WithBrokerDeadLetterChannel sets the channel messages are moved to once they have been delivered the maximum number
+// of times
+//
+// Messages are dropped when no dead letter channel has been set
+func WithBrokerDeadLetterChannel(channel string) BrokerOption {
+	return func(broker *Broker) {
+		broker.deadLetterChannel = channel
+	}
+}
+
+// WithBrokerLogger sets the log.Logger for Broker
+func WithBrokerLogger(logger log.Logger) BrokerOption {
+	return func(broker *Broker) {
+		broker.logger = logger
+	}
+}
@@ -0,0 +1,298 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/grpc/brokerpb"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/retry"
+)
+
+// Broker defaults
+const (
+	DefaultBrokerChannelBufferSize = 1024
+	DefaultBrokerAckTimeout        = 30 * time.Second
+	DefaultBrokerMaxDeliveries     = 10
+)
+
+type brokerMessage struct {
+	id         string
+	event      []byte
+	deliveries int
+}
+
+type brokerAck struct {
+	id       string
+	accepted bool
+}
+
+// Broker hosts channels in memory and serves the broker service to Producers and Consumers
+//
+// Messages are queued until a listener is available. Each message is delivered to one of the listeners of a
+// channel. A message that is rejected by its listener, or has not been acknowledged when its listener goes away, is
+// delivered again once the redelivery backoff has passed. Messages that have been delivered the maximum number of
+// times are moved to the dead letter channel, or dropped when no dead letter channel has been set
+type Broker struct {
+	channels          map[string]chan brokerMessage
+	listeners         map[string]chan brokerAck
+	channelBufferSize int
+	ackTimeout        time.Duration
+	maxDeliveries     int
+	redelivery        *retry.Backoff
+	deadLetterChannel string
+	logger            log.Logger
+	done              chan struct{}
+	close             sync.Once
+	mu                sync.Mutex
+}
+
+// NewBroker constructs a new Broker
+func NewBroker(options ...BrokerOption) *Broker {
+	b := &Broker{
+		channels:          map[string]chan brokerMessage{},
+		listeners:         map[string]chan brokerAck{},
+		channelBufferSize: DefaultBrokerChannelBufferSize,
+		ackTimeout:        DefaultBrokerAckTimeout,
+		maxDeliveries:     DefaultBrokerMaxDeliveries,
+		redelivery:        retry.NewExponentialBackoff(),
+		logger:            log.DefaultLogger,
+		done:              make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	b.logger.Trace("grpc.Broker constructed")
+
+	return b
+}
+
+// Close ends all open listen streams
+func (b *Broker) Close(context.Context) error {
+	b.close.Do(func() {
+		close(b.done)
+	})
+
+	b.logger.Trace("closing broker")
+	return nil
+}
+
+func (b *Broker) publish(ctx context.Context, event *wrapperspb.BytesValue) (*emptypb.Empty, error) {
+	channel := metadataValue(ctx, channelKey)
+	if channel == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing channel")
+	}
+
+	message, err := cloudevents.UnmarshalStructured(event.GetValue())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	select {
+	case b.channel(channel) <- brokerMessage{id: message.ID(), event: event.GetValue()}:
+	case <-ctx.Done():
+		return nil, status.Error(codes.Canceled, ctx.Err().Error())
+	case <-b.done:
+		return nil, status.Error(codes.Unavailable, "broker is closed")
+	}
+
+	b.logger.Trace("message published to channel", log.String("Channel", channel), log.String("MessageID", message.ID()))
+
+	return &emptypb.Empty{}, nil
+}
+
+func (b *Broker) ack(ctx context.Context, messageID *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return b.settle(ctx, brokerAck{id: messageID.GetValue(), accepted: true})
+}
+
+func (b *Broker) nack(ctx context.Context, messageID *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return b.settle(ctx, brokerAck{id: messageID.GetValue(), accepted: false})
+}
+
+func (b *Broker) settle(ctx context.Context, ack brokerAck) (*emptypb.Empty, error) {
+	listenerID := metadataValue(ctx, listenerIDKey)
+
+	b.mu.Lock()
+	acks, exists := b.listeners[listenerID]
+	b.mu.Unlock()
+
+	if !exists {
+		return nil, status.Error(codes.NotFound, "unknown listener")
+	}
+
+	select {
+	case acks <- ack:
+	default:
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (b *Broker) listen(channel *wrapperspb.StringValue, stream brokerpb.Broker_ListenServer) error {
+	listenerID := uuid.New().String()
+
+	logger := b.logger.Sub(
+		log.String("Channel", channel.GetValue()),
+		log.String("ListenerID", listenerID),
+	)
+
+	err := stream.SendHeader(metadata.Pairs(listenerIDKey, listenerID))
+	if err != nil {
+		return err
+	}
+
+	acks := make(chan brokerAck, 1)
+
+	b.mu.Lock()
+	b.listeners[listenerID] = acks
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.listeners, listenerID)
+		b.mu.Unlock()
+	}()
+
+	logger.Trace("listener connected")
+
+	ctx := stream.Context()
+	messages := b.channel(channel.GetValue())
+
+	for {
+		var message brokerMessage
+
+		select {
+		case message = <-messages:
+		case <-ctx.Done():
+			logger.Trace("listener disconnected")
+			return nil
+		case <-b.done:
+			return nil
+		}
+
+		message.deliveries++
+
+		err = stream.Send(wrapperspb.Bytes(message.event))
+		if err != nil {
+			logger.Error("error sending message to listener", log.Error(err))
+			b.requeue(channel.GetValue(), message, logger)
+			return err
+		}
+
+		var accepted bool
+		accepted, err = b.awaitAck(ctx, acks, message)
+		if err != nil {
+			logger.Warn("message was not acknowledged", log.Error(err), log.String("MessageID", message.id))
+			b.requeue(channel.GetValue(), message, logger)
+			return err
+		}
+
+		if !accepted {
+			logger.Trace("message was rejected", log.String("MessageID", message.id))
+			b.requeue(channel.GetValue(), message, logger)
+		}
+	}
+}
+
+func (b *Broker) awaitAck(ctx context.Context, acks chan brokerAck, message brokerMessage) (bool, error) {
+	timer := time.NewTimer(b.ackTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case ack := <-acks:
+			// listeners that cannot decode a message settle it without its id
+			if ack.id == message.id || ack.id == "" {
+				return ack.accepted, nil
+			}
+		case <-timer.C:
+			return false, status.Error(codes.DeadlineExceeded, "timed out waiting for acknowledgement")
+		case <-ctx.Done():
+			return false, status.Error(codes.Canceled, ctx.Err().Error())
+		case <-b.done:
+			return false, status.Error(codes.Unavailable, "broker is closed")
+		}
+	}
+}
+
+// requeue delivers the message again once the redelivery backoff has passed, or dead letters the message once it has
+// been delivered the maximum number of times
+func (b *Broker) requeue(channel string, message brokerMessage, logger log.Logger) {
+	if b.maxDeliveries > 0 && message.deliveries >= b.maxDeliveries {
+		b.deadLetter(message, logger)
+		return
+	}
+
+	delay := b.redelivery.Interval(message.deliveries)
+
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-b.done:
+			return
+		}
+
+		select {
+		case b.channel(channel) <- message:
+		case <-b.done:
+		}
+	}()
+}
+
+func (b *Broker) deadLetter(message brokerMessage, logger log.Logger) {
+	logger = logger.Sub(log.String("MessageID", message.id), log.Int("Deliveries", message.deliveries))
+
+	if b.deadLetterChannel == "" {
+		logger.Error("dropping message that has reached the maximum deliveries")
+		return
+	}
+
+	logger.Warn("moving message that has reached the maximum deliveries to the dead letter channel",
+		log.String("DeadLetterChannel", b.deadLetterChannel),
+	)
+
+	message.deliveries = 0
+
+	go func() {
+		select {
+		case b.channel(b.deadLetterChannel) <- message:
+		case <-b.done:
+		}
+	}()
+}
+
+func (b *Broker) channel(channel string) chan brokerMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	messages, exists := b.channels[channel]
+	if !exists {
+		messages = make(chan brokerMessage, b.channelBufferSize)
+		b.channels[channel] = messages
+	}
+
+	return messages
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,342 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	edatgrpc "github.com/stackus/edat/grpc"
+	"github.com/stackus/edat/grpc/brokerpb"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/retry"
+)
+
+func startBroker(t *testing.T, brokerOptions []edatgrpc.BrokerOption, options ...grpc.ServerOption) (*edatgrpc.Broker, *grpc.ClientConn) {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(options...)
+	broker := edatgrpc.NewBroker(append([]edatgrpc.BrokerOption{
+		edatgrpc.WithBrokerAckTimeout(time.Second),
+		edatgrpc.WithBrokerRedeliveryBackoff(retry.NewConstantBackoff(retry.WithBackoffInitialInterval(10 * time.Millisecond))),
+	}, brokerOptions...)...)
+	edatgrpc.RegisterBrokerServer(server, broker)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = broker.Close(context.Background())
+		server.Stop()
+	})
+
+	return broker, conn
+}
+
+func receive(t *testing.T, messages <-chan msg.Message) msg.Message {
+	t.Helper()
+	select {
+	case message := <-messages:
+		return message
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for message")
+	}
+	return nil
+}
+
+func TestBroker_PublishListen(t *testing.T) {
+	_, conn := startBroker(t, nil)
+
+	producer := edatgrpc.NewProducer(conn)
+	consumer := edatgrpc.NewConsumer(conn)
+
+	message := msg.NewMessage([]byte(`{"OrderID":"order-id"}`), msg.WithHeaders(map[string]string{
+		msg.MessageEventName:     "orders.OrderCreated",
+		msg.MessageCorrelationID: "correlation-id",
+	}))
+
+	// messages are held by the broker until a listener is available
+	err := producer.Send(context.Background(), "orders", message)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	received := make(chan msg.Message, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Listen(context.Background(), "orders", func(ctx context.Context, m msg.Message) error {
+			received <- m
+			return nil
+		})
+	}()
+
+	got := receive(t, received)
+	if got.ID() != message.ID() {
+		t.Errorf("ID() = %v, want %v", got.ID(), message.ID())
+	}
+	if got.Headers().Get(msg.MessageCorrelationID) != "correlation-id" {
+		t.Errorf("Headers() = %v, want correlation id", got.Headers())
+	}
+	if string(got.Payload()) != string(message.Payload()) {
+		t.Errorf("Payload() = %s, want %s", got.Payload(), message.Payload())
+	}
+
+	next := msg.NewMessage([]byte(`{}`))
+	err = producer.Send(context.Background(), "orders", next)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got = receive(t, received); got.ID() != next.ID() {
+		t.Errorf("ID() = %v, want %v", got.ID(), next.ID())
+	}
+
+	if err = consumer.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("Listen() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Listen() did not return after Close()")
+	}
+}
+
+func TestBroker_Redelivery(t *testing.T) {
+	_, conn := startBroker(t, nil)
+
+	producer := edatgrpc.NewProducer(conn)
+	message := msg.NewMessage([]byte(`{}`))
+
+	err := producer.Send(context.Background(), "orders", message)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// the first listener goes away before acknowledging the message
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan msg.Message, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- edatgrpc.NewConsumer(conn).Listen(ctx, "orders", func(_ context.Context, m msg.Message) error {
+			first <- m
+			cancel()
+			return nil
+		})
+	}()
+
+	if got := receive(t, first); got.ID() != message.ID() {
+		t.Errorf("ID() = %v, want %v", got.ID(), message.ID())
+	}
+	if err = <-done; err != nil {
+		t.Errorf("Listen() error = %v", err)
+	}
+
+	second := make(chan msg.Message, 1)
+	sCtx, sCancel := context.WithCancel(context.Background())
+	defer sCancel()
+	go func() {
+		_ = edatgrpc.NewConsumer(conn).Listen(sCtx, "orders", func(_ context.Context, m msg.Message) error {
+			second <- m
+			return nil
+		})
+	}()
+
+	if got := receive(t, second); got.ID() != message.ID() {
+		t.Errorf("redelivered ID() = %v, want %v", got.ID(), message.ID())
+	}
+}
+
+func TestBroker_RedeliverFailed(t *testing.T) {
+	_, conn := startBroker(t, nil)
+
+	producer := edatgrpc.NewProducer(conn)
+	failed := msg.NewMessage([]byte(`{}`))
+	next := msg.NewMessage([]byte(`{}`))
+
+	for _, message := range []msg.Message{failed, next} {
+		if err := producer.Send(context.Background(), "orders", message); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := 0
+	received := make(chan msg.Message, 3)
+	done := make(chan error, 1)
+	go func() {
+		done <- edatgrpc.NewConsumer(conn).Listen(ctx, "orders", func(_ context.Context, m msg.Message) error {
+			received <- m
+			if m.ID() == failed.ID() {
+				attempts++
+				if attempts == 1 {
+					return fmt.Errorf("consumer-error")
+				}
+			}
+			return nil
+		})
+	}()
+
+	// the failed message is rejected and delivered again behind the messages already queued
+	want := []string{failed.ID(), next.ID(), failed.ID()}
+	for _, id := range want {
+		if got := receive(t, received); got.ID() != id {
+			t.Errorf("ID() = %v, want %v", got.ID(), id)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Listen() error = %v", err)
+	}
+}
+
+func TestBroker_DeadLetter(t *testing.T) {
+	_, conn := startBroker(t, []edatgrpc.BrokerOption{
+		edatgrpc.WithBrokerMaxDeliveries(3),
+		edatgrpc.WithBrokerDeadLetterChannel("orders.dead"),
+	})
+
+	message := msg.NewMessage([]byte(`{}`))
+	if err := edatgrpc.NewProducer(conn).Send(context.Background(), "orders", message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := make(chan msg.Message, 10)
+	go func() {
+		_ = edatgrpc.NewConsumer(conn).Listen(ctx, "orders", func(_ context.Context, m msg.Message) error {
+			attempts <- m
+			return fmt.Errorf("consumer-error")
+		})
+	}()
+
+	dead := make(chan msg.Message, 1)
+	go func() {
+		_ = edatgrpc.NewConsumer(conn).Listen(ctx, "orders.dead", func(_ context.Context, m msg.Message) error {
+			dead <- m
+			return nil
+		})
+	}()
+
+	if got := receive(t, dead); got.ID() != message.ID() {
+		t.Errorf("dead letter ID() = %v, want %v", got.ID(), message.ID())
+	}
+
+	// the message is not delivered to the original channel again once it has been dead lettered
+	time.Sleep(50 * time.Millisecond)
+	if got := len(attempts); got != 3 {
+		t.Errorf("deliveries = %d, want 3", got)
+	}
+}
+
+func TestBroker_NackWithoutID(t *testing.T) {
+	_, conn := startBroker(t, nil)
+
+	message := msg.NewMessage([]byte(`{}`))
+	if err := edatgrpc.NewProducer(conn).Send(context.Background(), "orders", message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := brokerpb.NewBrokerClient(conn)
+	stream, err := client.Listen(ctx, wrapperspb.String("orders"))
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatalf("Header() error = %v", err)
+	}
+	ackCtx := metadata.AppendToOutgoingContext(ctx, "edat-listener-id", header.Get("edat-listener-id")[0])
+
+	// a listener that cannot decode a message rejects it without its id and is sent it again
+	for i := 0; i < 2; i++ {
+		if _, err = stream.Recv(); err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if _, err = client.Nack(ackCtx, wrapperspb.String("")); err != nil {
+			t.Fatalf("Nack() error = %v", err)
+		}
+	}
+}
+
+func TestBroker_RequestContext(t *testing.T) {
+	correlationIDs := make(chan string, 1)
+	capture := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		correlationIDs <- core.GetCorrelationID(ctx)
+		return handler(ctx, req)
+	}
+
+	_, conn := startBroker(t, nil, grpc.ChainUnaryInterceptor(edatgrpc.RequestContextUnaryServerInterceptor, capture))
+
+	ctx := core.SetRequestContext(context.Background(), "request-id", "correlation-id", "causation-id")
+
+	err := edatgrpc.NewProducer(conn).Send(ctx, "orders", msg.NewMessage([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := <-correlationIDs; got != "correlation-id" {
+		t.Errorf("CorrelationID = %v, want correlation-id", got)
+	}
+}
+
+func TestProducer_Tracer(t *testing.T) {
+	traceParents := make(chan string, 1)
+	capture := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		traceParents <- strings.Join(md.Get(core.TraceParentHeader), ",")
+		return handler(ctx, req)
+	}
+
+	_, conn := startBroker(t, nil, grpc.UnaryInterceptor(capture))
+
+	tracer := coretest.NewRecordingTracer()
+
+	err := edatgrpc.NewProducer(conn, edatgrpc.WithProducerTracer(tracer)).Send(context.Background(), "orders", msg.NewMessage([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("Send() spans = %d, want 1", len(spans))
+	}
+	if spans[0].Kind != core.SpanKindClient || !spans[0].Ended {
+		t.Errorf("Send() span = %+v, want an ended client span", spans[0])
+	}
+	if got := <-traceParents; got != spans[0].Context.TraceParent() {
+		t.Errorf("%s = %v, want %v", core.TraceParentHeader, got, spans[0].Context.TraceParent())
+	}
+}
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/grpc/brokerpb"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/msg"
+)
+
+// Consumer implements msg.Consumer by listening to the channels of a Broker
+//
+// Each received message is acknowledged once the msg.ReceiveMessageFunc has handled it without error. Messages that
+// fail, or that cannot be decoded, are rejected and the Broker delivers them again
+type Consumer struct {
+	client brokerpb.BrokerClient
+	logger log.Logger
+	done   chan struct{}
+	close  sync.Once
+}
+
+var _ msg.Consumer = (*Consumer)(nil)
+
+// NewConsumer constructs a new Consumer
+func NewConsumer(cc grpc.ClientConnInterface, options ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		client: brokerpb.NewBrokerClient(cc),
+		logger: log.DefaultLogger,
+		done:   make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.logger.Trace("grpc.Consumer constructed")
+
+	return c
+}
+
+// Listen implements msg.Consumer.Listen
+func (c *Consumer) Listen(ctx context.Context, channel string, consumer msg.ReceiveMessageFunc) error {
+	lCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-lCtx.Done():
+		}
+	}()
+
+	logger := c.logger.Sub(log.String("Channel", channel))
+
+	stream, err := c.client.Listen(outgoingContext(lCtx), wrapperspb.String(channel))
+	if err != nil {
+		logger.Error("error opening listen stream", log.Error(err))
+		return c.streamError(lCtx, err)
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return c.streamError(lCtx, err)
+	}
+
+	vals := header.Get(listenerIDKey)
+	if len(vals) == 0 {
+		return fmt.Errorf("broker did not return a listener id for channel `%s`", channel)
+	}
+	listenerID := vals[0]
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return c.streamError(lCtx, err)
+		}
+
+		ackCtx := metadata.AppendToOutgoingContext(outgoingContext(lCtx), listenerIDKey, listenerID)
+
+		message, err := cloudevents.UnmarshalStructured(event.GetValue())
+		if err != nil {
+			// the message id is not known; the broker rejects the message it is waiting on
+			logger.Error("error decoding message", log.Error(err))
+			_, err = c.client.Nack(ackCtx, wrapperspb.String(""))
+			if err != nil {
+				logger.Error("error rejecting message", log.Error(err))
+				return c.streamError(lCtx, err)
+			}
+			continue
+		}
+
+		err = consumer(lCtx, message)
+		if err != nil {
+			logger.Error("error consuming message", log.Error(err), log.String("MessageID", message.ID()))
+			_, err = c.client.Nack(ackCtx, wrapperspb.String(message.ID()))
+		} else {
+			_, err = c.client.Ack(ackCtx, wrapperspb.String(message.ID()))
+		}
+		if err != nil {
+			logger.Error("error acknowledging message", log.Error(err), log.String("MessageID", message.ID()))
+			return c.streamError(lCtx, err)
+		}
+	}
+}
+
+// Close implements msg.Consumer.Close
+func (c *Consumer) Close(context.Context) error {
+	c.close.Do(func() {
+		close(c.done)
+	})
+
+	c.logger.Trace("closing message source")
+	return nil
+}
+
+func (c *Consumer) streamError(ctx context.Context, err error) error {
+	// streams that end because the listener has been stopped are not errors
+	if err == io.EOF || ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
@@ -2,6 +2,8 @@ package grpc
 
 import (
 	"context"
+	"io"
+	"sync"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
@@ -19,9 +21,12 @@ const (
 
 type clientStreamWrapper struct {
 	grpc.ClientStream
+	span          core.Span
+	serverStreams bool
+	end           sync.Once
 }
 
-func (s clientStreamWrapper) Context() context.Context {
+func (s *clientStreamWrapper) Context() context.Context {
 	ctx := s.ClientStream.Context()
 
 	md := metadata.New(map[string]string{
@@ -29,43 +34,103 @@ func (s clientStreamWrapper) Context() context.Context {
 		correlationIDKey: core.GetCorrelationID(ctx),
 		causationIDKey:   core.GetCausationID(ctx),
 	})
+	setTraceMetadata(ctx, md)
 
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
+// RecvMsg ends the span of the stream once the stream has finished
+//
+// Streams finish when RecvMsg returns an error, io.EOF included, or after the response of a stream that does not
+// have server streaming
+func (s *clientStreamWrapper) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == io.EOF:
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	case !s.serverStreams:
+		s.finish(nil)
+	}
+
+	return err
+}
+
+func (s *clientStreamWrapper) finish(err error) {
+	s.end.Do(func() {
+		if err != nil {
+			s.span.RecordError(err)
+		}
+		s.span.End()
+	})
+}
+
 type serverStreamWrapper struct {
 	grpc.ServerStream
+	ctx context.Context
 }
 
 func (s serverStreamWrapper) Context() context.Context {
-	ctx := s.ServerStream.Context()
+	return s.ctx
+}
 
-	requestID := uuid.New().String()
-	correlationID := requestID
-	causationID := requestID
+// Unary
 
-	md, _ := metadata.FromIncomingContext(ctx)
-	vals := md.Get(requestIDKey)
-	if len(vals) > 0 {
-		requestID = vals[0]
+func RequestContextUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, span := core.DefaultTracer.Start(incomingContext(ctx), info.FullMethod, core.SpanKindServer)
+	defer span.End()
+
+	resp, err = handler(ctx, req)
+	if err != nil {
+		span.RecordError(err)
 	}
 
-	vals = md.Get(correlationIDKey)
-	if len(vals) > 0 {
-		correlationID = vals[0]
+	return resp, err
+}
+
+func RequestContextUnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := core.DefaultTracer.Start(ctx, method, core.SpanKindClient)
+	defer span.End()
+
+	err := invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	if err != nil {
+		span.RecordError(err)
 	}
 
-	vals = md.Get(causationIDKey)
-	if len(vals) > 0 {
-		causationID = vals[0]
+	return err
+}
+
+// Stream
+
+func RequestContextStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := core.DefaultTracer.Start(incomingContext(ss.Context()), info.FullMethod, core.SpanKindServer)
+	defer span.End()
+
+	err := handler(srv, serverStreamWrapper{ss, ctx})
+	if err != nil {
+		span.RecordError(err)
 	}
 
-	return core.SetRequestContext(ctx, requestID, correlationID, causationID)
+	return err
 }
 
-// Unary
+func RequestContextStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	// the span is ended by the wrapper once the stream has finished
+	ctx, span := core.DefaultTracer.Start(ctx, method, core.SpanKindClient)
+
+	stream, err := streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	if err != nil {
+		span.RecordError(err)
+		span.End()
+		return nil, err
+	}
+	return &clientStreamWrapper{ClientStream: stream, span: span, serverStreams: desc.ServerStreams}, nil
+}
 
-func RequestContextUnaryServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+// incomingContext sets the request context and trace context from the incoming metadata
+func incomingContext(ctx context.Context) context.Context {
 	requestID := uuid.New().String()
 	correlationID := requestID
 	causationID := requestID
@@ -86,10 +151,22 @@ func RequestContextUnaryServerInterceptor(ctx context.Context, req interface{},
 		causationID = vals[0]
 	}
 
-	return handler(core.SetRequestContext(ctx, requestID, correlationID, causationID), req)
+	ctx = core.SetRequestContext(ctx, requestID, correlationID, causationID)
+
+	var traceParent, traceState string
+	if vals = md.Get(core.TraceParentHeader); len(vals) > 0 {
+		traceParent = vals[0]
+	}
+
+	if vals = md.Get(core.TraceStateHeader); len(vals) > 0 {
+		traceState = vals[0]
+	}
+
+	return core.ContextWithTraceParent(ctx, traceParent, traceState)
 }
 
-func RequestContextUnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+// outgoingContext sets the outgoing metadata from the request context and trace context
+func outgoingContext(ctx context.Context) context.Context {
 	// RPC calls are request boundaries
 	requestID := uuid.New().String()
 	correlationID := core.GetCorrelationID(ctx)
@@ -105,20 +182,19 @@ func RequestContextUnaryClientInterceptor(ctx context.Context, method string, re
 		correlationIDKey: correlationID,
 		causationIDKey:   causationID,
 	})
+	setTraceMetadata(ctx, md)
 
-	return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+	return metadata.NewOutgoingContext(ctx, md)
 }
 
-// Stream
-
-func RequestContextStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	return handler(srv, serverStreamWrapper{ss})
-}
+func setTraceMetadata(ctx context.Context, md metadata.MD) {
+	sc := core.GetSpanContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
 
-func RequestContextStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-	stream, err := streamer(ctx, desc, cc, method, opts...)
-	if err != nil {
-		return nil, err
+	md.Set(core.TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		md.Set(core.TraceStateHeader, sc.TraceState)
 	}
-	return clientStreamWrapper{stream}, nil
 }
@@ -0,0 +1,110 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	edatgrpc "github.com/stackus/edat/grpc"
+)
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recvs []error
+}
+
+func (s *fakeClientStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeClientStream) RecvMsg(interface{}) error {
+	err := s.recvs[0]
+	s.recvs = s.recvs[1:]
+	return err
+}
+
+func TestRequestContextStreamClientInterceptor(t *testing.T) {
+	tracer := coretest.NewRecordingTracer()
+	defaultTracer := core.DefaultTracer
+	core.DefaultTracer = tracer
+	t.Cleanup(func() { core.DefaultTracer = defaultTracer })
+
+	errStream := fmt.Errorf("stream-error")
+
+	tests := map[string]struct {
+		serverStreams bool
+		streamErr     error
+		recvs         []error
+		wantOpen      int
+		wantErrors    int
+	}{
+		"ServerStreaming": {
+			serverStreams: true,
+			recvs:         []error{nil, nil, io.EOF},
+			wantOpen:      2,
+			wantErrors:    0,
+		},
+		"ServerStreamingError": {
+			serverStreams: true,
+			recvs:         []error{nil, errStream},
+			wantOpen:      1,
+			wantErrors:    1,
+		},
+		"ClientStreaming": {
+			serverStreams: false,
+			recvs:         []error{nil},
+			wantOpen:      0,
+			wantErrors:    0,
+		},
+		"StreamerError": {
+			serverStreams: true,
+			streamErr:     errStream,
+			wantOpen:      0,
+			wantErrors:    1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracer.Reset()
+
+			streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				if tt.streamErr != nil {
+					return nil, tt.streamErr
+				}
+				return &fakeClientStream{recvs: tt.recvs}, nil
+			}
+
+			stream, err := edatgrpc.RequestContextStreamClientInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: tt.serverStreams}, nil, "/test/Stream", streamer)
+			if (err != nil) != (tt.streamErr != nil) {
+				t.Fatalf("RequestContextStreamClientInterceptor() error = %v, want %v", err, tt.streamErr)
+			}
+
+			// the span must remain open until the stream has finished
+			open := 0
+			if stream != nil {
+				for range tt.recvs {
+					_ = stream.RecvMsg(nil)
+					if !tracer.Spans()[0].Ended {
+						open++
+					}
+				}
+			}
+			if open != tt.wantOpen {
+				t.Errorf("span was open after %d messages, want %d", open, tt.wantOpen)
+			}
+
+			span := tracer.Spans()[0]
+			if !span.Ended {
+				t.Errorf("span was not ended")
+			}
+			if len(span.Errors) != tt.wantErrors {
+				t.Errorf("span errors = %v, want %d", span.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
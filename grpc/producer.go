@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/grpc/brokerpb"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/msg"
+)
+
+// Producer implements msg.Producer by publishing messages to a Broker
+//  conn, err := grpc.Dial(brokerAddress, grpc.WithInsecure())
+//  producer := edatgrpc.NewProducer(conn)
+type Producer struct {
+	client brokerpb.BrokerClient
+	tracer core.Tracer
+	logger log.Logger
+}
+
+var _ msg.Producer = (*Producer)(nil)
+
+// NewProducer constructs a new Producer
+func NewProducer(cc grpc.ClientConnInterface, options ...ProducerOption) *Producer {
+	p := &Producer{
+		client: brokerpb.NewBrokerClient(cc),
+		tracer: core.DefaultTracer,
+		logger: log.DefaultLogger,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	p.logger.Trace("grpc.Producer constructed")
+
+	return p
+}
+
+// Send implements msg.Producer.Send
+func (p *Producer) Send(ctx context.Context, channel string, message msg.Message) error {
+	logger := p.logger.Sub(
+		log.String("Channel", channel),
+		log.String("MessageID", message.ID()),
+	)
+
+	event, err := cloudevents.MarshalStructured(message)
+	if err != nil {
+		logger.Error("error encoding message", log.Error(err))
+		return err
+	}
+
+	ctx, span := p.tracer.Start(ctx, publishMethod, core.SpanKindClient)
+	defer span.End()
+
+	ctx = metadata.AppendToOutgoingContext(outgoingContext(ctx), channelKey, channel)
+
+	_, err = p.client.Publish(ctx, wrapperspb.Bytes(event))
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("error publishing message to broker", log.Error(err))
+		return err
+	}
+
+	logger.Trace("message published to broker")
+
+	return nil
+}
+
+// Close implements msg.Producer.Close
+//
+// The connection to the Broker is owned by the caller and is not closed
+func (p *Producer) Close(context.Context) error {
+	p.logger.Trace("closing message destination")
+	return nil
+}
@@ -0,0 +1,23 @@
+package grpc
+
+import (
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/log"
+)
+
+// ProducerOption options for Producer
+type ProducerOption func(*Producer)
+
+// WithProducerTracer sets the core.Tracer used to start a client span for each message published by the Producer
+func WithProducerTracer(tracer core.Tracer) ProducerOption {
+	return func(producer *Producer) {
+		producer.tracer = tracer
+	}
+}
+
+// WithProducerLogger sets the log.Logger for Producer
+func WithProducerLogger(logger log.Logger) ProducerOption {
+	return func(producer *Producer) {
+		producer.logger = logger
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/msg"
+)
+
+// Consumer defaults
+const (
+	// DefaultConsumerMaxBodySize is the largest request body in bytes that will be accepted
+	DefaultConsumerMaxBodySize = 1 << 20
+	// DefaultConsumerSignatureTolerance is how far the signed timestamp of a request may be from the current time
+	DefaultConsumerSignatureTolerance = 5 * time.Minute
+)
+
+// Consumer implements msg.Consumer by receiving messages that are POSTed to its http.Handler
+//
+// The channel is taken from the ChannelHeader, or from the request path when the header is missing. Mount the consumer
+// using http.StripPrefix when it does not handle requests at the root path
+//  consumer := edathttp.NewConsumer(edathttp.WithConsumerSecret(secret))
+//  mux.Handle("/webhooks/", http.StripPrefix("/webhooks", consumer))
+//
+// Both structured and binary mode CloudEvents are accepted. Only structured mode CloudEvents are accepted when
+// a secret has been set, because the signature does not cover the attributes sent as headers in the binary mode
+type Consumer struct {
+	receivers   map[string]msg.ReceiveMessageFunc
+	secret      []byte
+	tolerance   time.Duration
+	maxBodySize int64
+	logger      log.Logger
+	done        chan struct{}
+	close       sync.Once
+	mu          sync.RWMutex
+}
+
+var _ msg.Consumer = (*Consumer)(nil)
+var _ http.Handler = (*Consumer)(nil)
+
+// NewConsumer constructs a new Consumer
+func NewConsumer(options ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		receivers:   map[string]msg.ReceiveMessageFunc{},
+		tolerance:   DefaultConsumerSignatureTolerance,
+		maxBodySize: DefaultConsumerMaxBodySize,
+		logger:      log.DefaultLogger,
+		done:        make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.logger.Trace("http.Consumer constructed")
+
+	return c
+}
+
+// Listen implements msg.Consumer.Listen
+func (c *Consumer) Listen(ctx context.Context, channel string, consumer msg.ReceiveMessageFunc) error {
+	c.mu.Lock()
+	if _, exists := c.receivers[channel]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("already listening to channel `%s`", channel)
+	}
+	c.receivers[channel] = consumer
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.receivers, channel)
+		c.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-c.done:
+	}
+
+	return nil
+}
+
+// Close implements msg.Consumer.Close
+func (c *Consumer) Close(context.Context) error {
+	c.close.Do(func() {
+		close(c.done)
+	})
+
+	c.logger.Trace("closing message source")
+	return nil
+}
+
+// ServeHTTP implements http.Handler.ServeHTTP
+func (c *Consumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	channel, err := requestChannel(r)
+	if err != nil || channel == "" {
+		http.Error(w, "invalid channel", http.StatusNotFound)
+		return
+	}
+
+	logger := c.logger.Sub(log.String("Channel", channel))
+
+	c.mu.RLock()
+	consumer, exists := c.receivers[channel]
+	c.mu.RUnlock()
+
+	if !exists {
+		logger.Warn("no listener for channel")
+		http.Error(w, "unknown channel", http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, c.maxBodySize))
+	if err != nil {
+		logger.Error("error reading request body", log.Error(err))
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	structured := mediaType == cloudevents.StructuredContentType
+
+	if c.secret != nil {
+		if !structured {
+			logger.Warn("signed messages must be structured mode cloudevents")
+			http.Error(w, "structured mode cloudevents are required", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		timestamp := r.Header.Get(TimestampHeader)
+		if !withinTolerance(timestamp, time.Now(), c.tolerance) {
+			logger.Warn("invalid message signature timestamp")
+			http.Error(w, "invalid signature timestamp", http.StatusUnauthorized)
+			return
+		}
+
+		if !VerifySignature(c.secret, timestamp, channel, body, r.Header.Get(SignatureHeader)) {
+			logger.Warn("invalid message signature")
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	message, err := c.decode(structured, r.Header, body)
+	if err != nil {
+		logger.Error("error decoding message", log.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = consumer(r.Context(), message)
+	if err != nil {
+		logger.Error("error consuming message", log.Error(err), log.String("MessageID", message.ID()))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestChannel returns the channel from the ChannelHeader, falling back to the request path
+func requestChannel(r *http.Request) (string, error) {
+	if channel := r.Header.Get(ChannelHeader); channel != "" {
+		return channel, nil
+	}
+
+	return url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/"))
+}
+
+func (c *Consumer) decode(structured bool, header http.Header, body []byte) (msg.Message, error) {
+	if structured {
+		return cloudevents.UnmarshalStructured(body)
+	}
+
+	return cloudevents.ReadBinary(header, body)
+}
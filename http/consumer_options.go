@@ -0,0 +1,40 @@
+package http
+
+import (
+	"time"
+
+	"github.com/stackus/edat/log"
+)
+
+// ConsumerOption options for Consumer
+type ConsumerOption func(*Consumer)
+
+// WithConsumerSecret sets the shared secret used to verify the signatures of received messages
+//
+// Requests without a valid signature are rejected when a secret has been set
+func WithConsumerSecret(secret []byte) ConsumerOption {
+	return func(consumer *Consumer) {
+		consumer.secret = secret
+	}
+}
+
+// WithConsumerSignatureTolerance sets how far the signed timestamp of a request may be from the current time
+func WithConsumerSignatureTolerance(tolerance time.Duration) ConsumerOption {
+	return func(consumer *Consumer) {
+		consumer.tolerance = tolerance
+	}
+}
+
+// WithConsumerMaxBodySize sets the largest request body in bytes that the Consumer will accept
+func WithConsumerMaxBodySize(maxBodySize int64) ConsumerOption {
+	return func(consumer *Consumer) {
+		consumer.maxBodySize = maxBodySize
+	}
+}
+
+// WithConsumerLogger sets the log.Logger for Consumer
+func WithConsumerLogger(logger log.Logger) ConsumerOption {
+	return func(consumer *Consumer) {
+		consumer.logger = logger
+	}
+}
@@ -17,6 +17,8 @@ const (
 )
 
 // RequestContext is an http.Handler middleware that sets the id, correlation, and causation ids into context
+//
+// Incoming W3C trace context headers are continued by a server span started with core.DefaultTracer
 func RequestContext(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := r.Header.Get(RequestIDHeader)
@@ -34,6 +36,10 @@ func RequestContext(next http.Handler) http.Handler {
 
 		ctx := core.SetRequestContext(r.Context(), requestID, correlationID, causationID)
 
+		ctx = core.ContextWithTraceParent(ctx, r.Header.Get(core.TraceParentHeader), r.Header.Get(core.TraceStateHeader))
+		ctx, span := core.DefaultTracer.Start(ctx, r.Method+" "+r.URL.Path, core.SpanKindServer)
+		defer span.End()
+
 		w.Header().Set(RequestIDHeader, core.GetRequestID(ctx))
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -45,3 +51,16 @@ func SetResponseHeaders(ctx context.Context, w http.ResponseWriter) {
 	w.Header().Set(CorrelationIDHeader, core.GetCorrelationID(ctx))
 	w.Header().Set(CausationIDHeader, core.GetCausationID(ctx))
 }
+
+// SetTraceHeaders puts the W3C trace context of the current span into the headers of an outgoing http request
+func SetTraceHeaders(ctx context.Context, header http.Header) {
+	sc := core.GetSpanContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(core.TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(core.TraceStateHeader, sc.TraceState)
+	}
+}
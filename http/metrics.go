@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bufio"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stackus/edat/metrics"
+)
+
+// MetricsContentType is the content type of the Prometheus text exposition format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler is an http.Handler that exposes the gathered metrics in the Prometheus text exposition format
+//  registry := metrics.NewMemoryRegistry()
+//  metrics.DefaultRegistry = registry
+//  mux.Handle("/metrics", edathttp.MetricsHandler(registry))
+func MetricsHandler(gatherer metrics.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MetricsContentType)
+
+		bw := bufio.NewWriter(w)
+		defer bw.Flush()
+
+		for _, family := range gatherer.Gather() {
+			writeFamily(bw, family)
+		}
+	})
+}
+
+func writeFamily(w *bufio.Writer, family metrics.Family) {
+	if family.Help != "" {
+		w.WriteString("# HELP " + family.Name + " " + escapeHelp(family.Help) + "\n")
+	}
+	w.WriteString("# TYPE " + family.Name + " " + family.Type.String() + "\n")
+
+	for _, sample := range family.Samples {
+		if family.Type != metrics.HistogramType {
+			writeSample(w, family.Name, sample.Labels, "", "", sample.Value)
+			continue
+		}
+
+		for _, bucket := range sample.Buckets {
+			writeSample(w, family.Name+"_bucket", sample.Labels, "le", formatFloat(bucket.UpperBound), float64(bucket.Count))
+		}
+		writeSample(w, family.Name+"_sum", sample.Labels, "", "", sample.Sum)
+		writeSample(w, family.Name+"_count", sample.Labels, "", "", float64(sample.Count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels metrics.Labels, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+
+	names := make([]string, 0, len(labels))
+	for labelName := range labels {
+		names = append(names, labelName)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 || extraName != "" {
+		pairs := make([]string, 0, len(names)+1)
+		for _, labelName := range names {
+			pairs = append(pairs, labelName+`="`+escapeLabelValue(labels[labelName])+`"`)
+		}
+		if extraName != "" {
+			pairs = append(pairs, extraName+`="`+extraValue+`"`)
+		}
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
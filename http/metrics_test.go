@@ -0,0 +1,42 @@
+package http_test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	edathttp "github.com/stackus/edat/http"
+	"github.com/stackus/edat/metrics"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	registry := metrics.NewMemoryRegistry()
+	registry.Counter("edat_messages_published_total", "Number of messages published").Add(2, metrics.Labels{"channel": `say "hi"`})
+	registry.Gauge("edat_sagas_active", "Number of sagas\nthat have not ended").Set(1, metrics.Labels{"saga": "order"})
+	registry.Histogram("edat_message_publish_duration_seconds", "", []float64{0.1, 1}).Observe(0.5, nil)
+
+	want := `# TYPE edat_message_publish_duration_seconds histogram
+edat_message_publish_duration_seconds_bucket{le="0.1"} 0
+edat_message_publish_duration_seconds_bucket{le="1"} 1
+edat_message_publish_duration_seconds_bucket{le="+Inf"} 1
+edat_message_publish_duration_seconds_sum 0.5
+edat_message_publish_duration_seconds_count 1
+# HELP edat_messages_published_total Number of messages published
+# TYPE edat_messages_published_total counter
+edat_messages_published_total{channel="say \"hi\""} 2
+# HELP edat_sagas_active Number of sagas\nthat have not ended
+# TYPE edat_sagas_active gauge
+edat_sagas_active{saga="order"} 1
+`
+
+	w := httptest.NewRecorder()
+	edathttp.MetricsHandler(registry).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	if string(body) != want {
+		t.Errorf("MetricsHandler() body = %s, want %s", body, want)
+	}
+	if got := w.Result().Header.Get("Content-Type"); got != edathttp.MetricsContentType {
+		t.Errorf("MetricsHandler() content type = %s, want %s", got, edathttp.MetricsContentType)
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/retry"
+)
+
+// Producer defaults
+const (
+	// DefaultProducerMaxRetries is the number of attempts made to deliver a message before giving up
+	DefaultProducerMaxRetries = 5
+	// DefaultProducerTimeout is the time limit of each request made by the default http.Client of the Producer
+	DefaultProducerTimeout = 30 * time.Second
+)
+
+// Producer implements msg.Producer by POSTing messages as structured mode CloudEvents to webhook URLs
+//
+// Messages are sent to the URL of the channel, which defaults to the channel name appended to the base URL. The channel
+// is also sent in the ChannelHeader, and is signed along with the message when a secret has been set
+//  producer := edathttp.NewProducer("https://example.com/webhooks", edathttp.WithProducerSecret(secret))
+type Producer struct {
+	baseURL     string
+	channelURLs map[string]string
+	client      *http.Client
+	retryer     retry.Retryer
+	secret      []byte
+	tracer      core.Tracer
+	logger      log.Logger
+}
+
+var _ msg.Producer = (*Producer)(nil)
+
+// NewProducer constructs a new Producer
+func NewProducer(baseURL string, options ...ProducerOption) *Producer {
+	p := &Producer{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		channelURLs: map[string]string{},
+		client:      &http.Client{Timeout: DefaultProducerTimeout},
+		retryer:     retry.NewExponentialBackoff(retry.WithBackoffMaxRetries(DefaultProducerMaxRetries)),
+		tracer:      core.DefaultTracer,
+		logger:      log.DefaultLogger,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	p.logger.Trace("http.Producer constructed", log.String("BaseURL", p.baseURL))
+
+	return p
+}
+
+// Send implements msg.Producer.Send
+func (p *Producer) Send(ctx context.Context, channel string, message msg.Message) error {
+	logger := p.logger.Sub(
+		log.String("Channel", channel),
+		log.String("MessageID", message.ID()),
+	)
+
+	body, err := cloudevents.MarshalStructured(message)
+	if err != nil {
+		logger.Error("error encoding message", log.Error(err))
+		return err
+	}
+
+	channelURL := p.channelURL(channel)
+
+	err = p.retryer.Retry(ctx, func() error {
+		ctx, span := p.tracer.Start(ctx, http.MethodPost+" "+channel, core.SpanKindClient)
+		defer span.End()
+
+		err := p.post(ctx, channel, channelURL, body)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return err
+	})
+	if err != nil {
+		logger.Error("error sending message to webhook", log.Error(err))
+		return err
+	}
+
+	logger.Trace("message sent to webhook")
+
+	return nil
+}
+
+// Close implements msg.Producer.Close
+func (p *Producer) Close(context.Context) error {
+	p.logger.Trace("closing message destination")
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+func (p *Producer) channelURL(channel string) string {
+	if channelURL, exists := p.channelURLs[channel]; exists {
+		return channelURL
+	}
+
+	return p.baseURL + "/" + url.PathEscape(channel)
+}
+
+func (p *Producer) post(ctx context.Context, channel, channelURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channelURL, bytes.NewReader(body))
+	if err != nil {
+		return retry.DoNotRetry(err)
+	}
+
+	req.Header.Set("Content-Type", cloudevents.StructuredContentType)
+	req.Header.Set(ChannelHeader, channel)
+	if p.secret != nil {
+		// each attempt is signed again so that retries are not rejected for having an old timestamp
+		timestamp := SignatureTimestamp(time.Now())
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(p.secret, timestamp, channel, body))
+	}
+	SetTraceHeaders(ctx, req.Header)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return retry.DoNotRetry(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// drain the body so that the connection may be reused
+	io.Copy(ioutil.Discard, resp.Body) // nolint:errcheck
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+
+	// client errors will not be fixed by sending the same request again
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return retry.DoNotRetry(err)
+	}
+
+	return err
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/retry"
+)
+
+// ProducerOption options for Producer
+type ProducerOption func(*Producer)
+
+// WithProducerChannelURL sets the URL messages for the channel will be sent to instead of one derived from the base URL
+func WithProducerChannelURL(channel, channelURL string) ProducerOption {
+	return func(producer *Producer) {
+		producer.channelURLs[channel] = channelURL
+	}
+}
+
+// WithProducerSecret sets the shared secret used to sign the messages sent by the Producer
+func WithProducerSecret(secret []byte) ProducerOption {
+	return func(producer *Producer) {
+		producer.secret = secret
+	}
+}
+
+// WithProducerClient sets the http.Client used by the Producer
+func WithProducerClient(client *http.Client) ProducerOption {
+	return func(producer *Producer) {
+		producer.client = client
+	}
+}
+
+// WithProducerRetryer sets the retry strategy used by the Producer when a message cannot be delivered
+func WithProducerRetryer(retryer retry.Retryer) ProducerOption {
+	return func(producer *Producer) {
+		producer.retryer = retryer
+	}
+}
+
+// WithProducerTracer sets the core.Tracer used to start a client span for each request made by the Producer
+func WithProducerTracer(tracer core.Tracer) ProducerOption {
+	return func(producer *Producer) {
+		producer.tracer = tracer
+	}
+}
+
+// WithProducerLogger sets the log.Logger for Producer
+func WithProducerLogger(logger log.Logger) ProducerOption {
+	return func(producer *Producer) {
+		producer.logger = logger
+	}
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackus/edat/saga"
+)
+
+// SagaAdminHandler is an http.Handler that exposes saga instances for inspection
+//
+// Mount the handler using http.StripPrefix when it does not handle requests at the root path
+//
+//	mux.Handle("/admin/sagas/", http.StripPrefix("/admin/sagas", edathttp.SagaAdminHandler(store)))
+//
+// Routes:
+//
+//	GET /instances?saga=&state=&min_age=&idle=&offset=&limit=
+//	GET /counts?saga=
+//	POST /instances/{sagaName}/{sagaID}/{abort|retry|skip|fail}
+//
+// The state parameter may be repeated. The min_age and idle parameters are durations, such as 15m, and select the
+// instances created, or last updated, at least that long ago
+//
+// The intervention routes are available for the orchestrators added with WithSagaAdminOrchestrators. The request
+// body must be a JSON object with the reason for the intervention
+//
+//	{"reason": "payment provider outage"}
+//
+// The operator recorded with an intervention is returned by the SagaAdminAuthenticator added with
+// WithSagaAdminAuthenticator, and requests it does not authenticate are rejected. Without an authenticator the operator
+// is read from the request body as given; the handler must then be wrapped in middleware that authenticates callers
+//
+//	{"operator": "jane@example.com", "reason": "payment provider outage"}
+func SagaAdminHandler(querier saga.InstanceQuerier, options ...SagaAdminOption) http.Handler {
+	cfg := &sagaAdmin{
+		orchestrators: map[string]*saga.Orchestrator{},
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/instances/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/instances/"), "/")
+		if len(parts) != 3 {
+			http.NotFound(w, r)
+			return
+		}
+
+		orchestrator, exists := cfg.orchestrators[parts[0]]
+		if !exists {
+			http.Error(w, "unknown saga `"+parts[0]+"`", http.StatusNotFound)
+			return
+		}
+
+		var operation func(ctx context.Context, sagaID, operator, reason string) (*saga.Instance, error)
+		switch saga.InterventionAction(parts[2]) {
+		case saga.InterventionAbort:
+			operation = orchestrator.Abort
+		case saga.InterventionRetryStep:
+			operation = orchestrator.RetryStep
+		case saga.InterventionSkipStep:
+			operation = orchestrator.SkipStep
+		case saga.InterventionMarkFailed:
+			operation = orchestrator.MarkFailed
+		default:
+			http.Error(w, "unknown intervention `"+parts[2]+"`", http.StatusNotFound)
+			return
+		}
+
+		var req sagaInterventionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if cfg.authenticate != nil {
+			operator, err := cfg.authenticate(r)
+			if err != nil || operator == "" {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			req.Operator = operator
+		}
+
+		instance, err := operation(r.Context(), parts[1], req.Operator, req.Reason)
+		switch {
+		case errors.Is(err, saga.ErrInterventionIncomplete):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, saga.ErrInstanceNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, saga.ErrInterventionNotAllowed), errors.Is(err, saga.ErrInstanceConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, newSagaInstanceResponse(instance))
+	})
+
+	mux.HandleFunc("/instances", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		query, err := sagaInstanceQuery(r, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page, err := querier.Query(r.Context(), query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := sagaInstancesResponse{
+			Instances: make([]sagaInstanceResponse, 0, len(page.Instances)),
+			Total:     page.Total,
+			Offset:    query.Offset,
+			Limit:     query.Limit,
+		}
+		for _, instance := range page.Instances {
+			resp.Instances = append(resp.Instances, newSagaInstanceResponse(instance))
+		}
+
+		writeJSON(w, resp)
+	})
+
+	mux.HandleFunc("/counts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		counts, err := querier.Counts(r.Context(), r.URL.Query().Get("saga"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, counts)
+	})
+
+	return mux
+}
+
+// SagaAdminAuthenticator returns the operator that made the intervention request, or an error when the request
+// could not be authenticated
+type SagaAdminAuthenticator func(r *http.Request) (operator string, err error)
+
+type sagaAdmin struct {
+	orchestrators map[string]*saga.Orchestrator
+	authenticate  SagaAdminAuthenticator
+}
+
+type sagaInterventionRequest struct {
+	Operator string `json:"operator"`
+	Reason   string `json:"reason"`
+}
+
+type sagaInterventionResponse struct {
+	Action   saga.InterventionAction `json:"action"`
+	Operator string                  `json:"operator"`
+	Reason   string                  `json:"reason"`
+	Step     int                     `json:"step"`
+	State    saga.InstanceState      `json:"state"`
+	At       time.Time               `json:"at"`
+}
+
+type sagaInstancesResponse struct {
+	Instances []sagaInstanceResponse `json:"instances"`
+	Total     int                    `json:"total"`
+	Offset    int                    `json:"offset"`
+	Limit     int                    `json:"limit"`
+}
+
+type sagaInstanceResponse struct {
+	SagaID         string             `json:"sagaId"`
+	SagaName       string             `json:"sagaName"`
+	State          saga.InstanceState `json:"state"`
+	CurrentStep    int                `json:"currentStep"`
+	Retries        int                `json:"retries,omitempty"`
+	Deadline       *time.Time         `json:"deadline,omitempty"`
+	CorrelationKey string             `json:"correlationKey,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+
+	Interventions []sagaInterventionResponse `json:"interventions,omitempty"`
+}
+
+func newSagaInstanceResponse(instance *saga.Instance) sagaInstanceResponse {
+	resp := sagaInstanceResponse{
+		SagaID:         instance.SagaID(),
+		SagaName:       instance.SagaName(),
+		State:          instance.State(),
+		CurrentStep:    instance.CurrentStep(),
+		Retries:        instance.Retries(),
+		CorrelationKey: instance.CorrelationKey(),
+		CreatedAt:      instance.CreatedAt(),
+		UpdatedAt:      instance.UpdatedAt(),
+	}
+
+	if deadline := instance.Deadline(); !deadline.IsZero() {
+		resp.Deadline = &deadline
+	}
+
+	for _, intervention := range instance.Interventions() {
+		resp.Interventions = append(resp.Interventions, sagaInterventionResponse(intervention))
+	}
+
+	return resp
+}
+
+func sagaInstanceQuery(r *http.Request, now time.Time) (saga.InstanceQuery, error) {
+	params := r.URL.Query()
+
+	query := saga.InstanceQuery{
+		SagaName: params.Get("saga"),
+	}
+
+	for _, value := range params["state"] {
+		state := saga.InstanceState(value)
+		if !validInstanceState(state) {
+			return query, &paramError{name: "state", value: value}
+		}
+		query.States = append(query.States, state)
+	}
+
+	if value := params.Get("min_age"); value != "" {
+		age, err := time.ParseDuration(value)
+		if err != nil {
+			return query, &paramError{name: "min_age", value: value}
+		}
+		query.CreatedBefore = now.Add(-age)
+	}
+
+	if value := params.Get("idle"); value != "" {
+		idle, err := time.ParseDuration(value)
+		if err != nil {
+			return query, &paramError{name: "idle", value: value}
+		}
+		query.UpdatedBefore = now.Add(-idle)
+	}
+
+	var err error
+	if query.Offset, err = intParam(params.Get("offset"), "offset"); err != nil {
+		return query, err
+	}
+
+	if query.Limit, err = intParam(params.Get("limit"), "limit"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+func validInstanceState(state saga.InstanceState) bool {
+	for _, s := range saga.InstanceStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intParam(value, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, &paramError{name: name, value: value}
+	}
+
+	return i, nil
+}
+
+type paramError struct {
+	name  string
+	value string
+}
+
+func (e *paramError) Error() string {
+	return "invalid " + e.name + " parameter `" + e.value + "`"
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/stackus/edat/saga"
+)
+
+// SagaAdminOption options for SagaAdminHandler
+type SagaAdminOption func(*sagaAdmin)
+
+// WithSagaAdminOrchestrators adds the orchestrators that will perform interventions for their sagas
+func WithSagaAdminOrchestrators(orchestrators ...*saga.Orchestrator) SagaAdminOption {
+	return func(admin *sagaAdmin) {
+		for _, orchestrator := range orchestrators {
+			admin.orchestrators[orchestrator.SagaName()] = orchestrator
+		}
+	}
+}
+
+// WithSagaAdminAuthenticator sets the SagaAdminAuthenticator that identifies the operator making an intervention
+//
+// The operator in the request body is ignored when an authenticator is used
+func WithSagaAdminAuthenticator(authenticator SagaAdminAuthenticator) SagaAdminOption {
+	return func(admin *sagaAdmin) {
+		admin.authenticate = authenticator
+	}
+}
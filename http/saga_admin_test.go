@@ -0,0 +1,262 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	edathttp "github.com/stackus/edat/http"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/saga"
+)
+
+type adminDefinition struct{}
+
+func (adminDefinition) SagaName() string     { return "shipping" }
+func (adminDefinition) ReplyChannel() string { return "shipping" }
+func (adminDefinition) Steps() []saga.Step {
+	return []saga.Step{saga.NewLocalStep(func(context.Context, core.SagaData) error { return nil })}
+}
+func (adminDefinition) OnHook(saga.LifecycleHook, *saga.Instance) {}
+
+func TestSagaAdminHandler(t *testing.T) {
+	now := time.Now()
+
+	store := inmem.NewSagaInstanceStore()
+	instances := []*saga.Instance{
+		saga.NewSagaInstance("orders", "saga-1", nil, 1, false, false,
+			saga.WithInstanceCreatedAt(now.Add(-3*time.Hour)), saga.WithInstanceUpdatedAt(now.Add(-2*time.Hour))),
+		saga.NewSagaInstance("orders", "saga-2", nil, 0, false, true,
+			saga.WithInstanceCreatedAt(now.Add(-2*time.Hour)), saga.WithInstanceUpdatedAt(now.Add(-2*time.Hour))),
+		saga.NewSagaInstance("orders", "saga-3", nil, 0, false, true,
+			saga.WithInstanceCreatedAt(now.Add(-time.Minute)), saga.WithInstanceUpdatedAt(now.Add(-time.Minute))),
+		saga.NewSagaInstance("orders", "saga-4", nil, 2, true, false,
+			saga.WithInstanceCreatedAt(now.Add(-time.Hour)), saga.WithInstanceUpdatedAt(now.Add(-time.Hour))),
+		saga.NewSagaInstance("payments", "saga-5", nil, 0, true, true,
+			saga.WithInstanceCreatedAt(now.Add(-time.Hour)), saga.WithInstanceUpdatedAt(now.Add(-time.Hour))),
+		saga.NewSagaInstance("payments", "saga-6", nil, 1, false, true, saga.WithInstanceFailed(true),
+			saga.WithInstanceCreatedAt(now.Add(-time.Hour)), saga.WithInstanceUpdatedAt(now.Add(-time.Hour))),
+	}
+	for _, instance := range instances {
+		if err := store.Save(context.Background(), instance); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler := edathttp.SagaAdminHandler(store)
+
+	type page struct {
+		Instances []struct {
+			SagaID string `json:"sagaId"`
+			State  string `json:"state"`
+		} `json:"instances"`
+		Total int `json:"total"`
+	}
+
+	instanceTests := map[string]struct {
+		query      string
+		wantStatus int
+		wantIDs    []string
+		wantTotal  int
+	}{
+		"All":           {query: "", wantStatus: http.StatusOK, wantIDs: []string{"saga-1", "saga-2", "saga-4", "saga-5", "saga-6", "saga-3"}, wantTotal: 6},
+		"SagaName":      {query: "saga=payments", wantStatus: http.StatusOK, wantIDs: []string{"saga-5", "saga-6"}, wantTotal: 2},
+		"State":         {query: "state=compensating", wantStatus: http.StatusOK, wantIDs: []string{"saga-2", "saga-3"}, wantTotal: 2},
+		"States":        {query: "state=running&state=failed", wantStatus: http.StatusOK, wantIDs: []string{"saga-1", "saga-6"}, wantTotal: 2},
+		"StuckIdle":     {query: "state=compensating&idle=30m", wantStatus: http.StatusOK, wantIDs: []string{"saga-2"}, wantTotal: 1},
+		"MinAge":        {query: "min_age=90m", wantStatus: http.StatusOK, wantIDs: []string{"saga-1", "saga-2"}, wantTotal: 2},
+		"Page":          {query: "offset=1&limit=2", wantStatus: http.StatusOK, wantIDs: []string{"saga-2", "saga-4"}, wantTotal: 6},
+		"PastEnd":       {query: "offset=10", wantStatus: http.StatusOK, wantIDs: nil, wantTotal: 6},
+		"InvalidState":  {query: "state=stuck", wantStatus: http.StatusBadRequest},
+		"InvalidAge":    {query: "min_age=old", wantStatus: http.StatusBadRequest},
+		"InvalidOffset": {query: "offset=-1", wantStatus: http.StatusBadRequest},
+		"InvalidLimit":  {query: "limit=ten", wantStatus: http.StatusBadRequest},
+	}
+	for name, tt := range instanceTests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/instances?"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got page
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			var ids []string
+			for _, instance := range got.Instances {
+				ids = append(ids, instance.SagaID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("instances = %v, want %v", ids, tt.wantIDs)
+			}
+			if got.Total != tt.wantTotal {
+				t.Errorf("total = %v, want %v", got.Total, tt.wantTotal)
+			}
+		})
+	}
+
+	countTests := map[string]struct {
+		query string
+		want  map[string]int
+	}{
+		"All":      {query: "", want: map[string]int{"running": 1, "compensating": 2, "completed": 1, "compensated": 1, "failed": 1}},
+		"SagaName": {query: "saga=orders", want: map[string]int{"running": 1, "compensating": 2, "completed": 1, "compensated": 0, "failed": 0}},
+	}
+	for name, tt := range countTests {
+		t.Run("Counts"+name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/counts?"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, want %v", rec.Code, http.StatusOK)
+			}
+
+			var got map[string]int
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("counts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSagaAdminHandler_Interventions(t *testing.T) {
+	store := inmem.NewSagaInstanceStore()
+	instances := []*saga.Instance{
+		saga.NewSagaInstance("shipping", "saga-1", nil, 0, false, false),
+		saga.NewSagaInstance("shipping", "saga-2", nil, 0, true, false),
+	}
+	for _, instance := range instances {
+		if err := store.Save(context.Background(), instance); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orchestrator := saga.NewOrchestrator(adminDefinition{}, store, nil)
+	handler := edathttp.SagaAdminHandler(store, edathttp.WithSagaAdminOrchestrators(orchestrator))
+
+	const body = `{"operator": "jane@example.com", "reason": "carrier outage"}`
+
+	tests := map[string]struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		"MethodNotAllowed":    {method: http.MethodGet, path: "/instances/shipping/saga-1/fail", wantStatus: http.StatusMethodNotAllowed},
+		"UnknownSaga":         {method: http.MethodPost, path: "/instances/orders/saga-1/fail", body: body, wantStatus: http.StatusNotFound},
+		"UnknownIntervention": {method: http.MethodPost, path: "/instances/shipping/saga-1/pause", body: body, wantStatus: http.StatusNotFound},
+		"UnknownInstance":     {method: http.MethodPost, path: "/instances/shipping/saga-9/fail", body: body, wantStatus: http.StatusNotFound},
+		"InvalidBody":         {method: http.MethodPost, path: "/instances/shipping/saga-1/fail", body: "{", wantStatus: http.StatusBadRequest},
+		"MissingReason":       {method: http.MethodPost, path: "/instances/shipping/saga-1/fail", body: `{"operator": "jane@example.com"}`, wantStatus: http.StatusBadRequest},
+		"NotAllowed":          {method: http.MethodPost, path: "/instances/shipping/saga-2/fail", body: body, wantStatus: http.StatusConflict},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	t.Run("MarkFailed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/instances/shipping/saga-1/fail", strings.NewReader(body)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %v, want %v: %s", rec.Code, http.StatusOK, rec.Body)
+		}
+
+		var got struct {
+			State         string `json:"state"`
+			Interventions []struct {
+				Action   string `json:"action"`
+				Operator string `json:"operator"`
+				Reason   string `json:"reason"`
+				State    string `json:"state"`
+			} `json:"interventions"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if got.State != "failed" {
+			t.Errorf("state = %v, want failed", got.State)
+		}
+		if len(got.Interventions) != 1 {
+			t.Fatalf("interventions = %v, want 1", got.Interventions)
+		}
+		intervention := got.Interventions[0]
+		if intervention.Action != "fail" || intervention.Operator != "jane@example.com" || intervention.Reason != "carrier outage" || intervention.State != "running" {
+			t.Errorf("intervention = %+v", intervention)
+		}
+	})
+}
+
+func TestSagaAdminHandler_Authenticator(t *testing.T) {
+	store := inmem.NewSagaInstanceStore()
+	if err := store.Save(context.Background(), saga.NewSagaInstance("shipping", "saga-1", nil, 0, false, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := func(r *http.Request) (string, error) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			return "", fmt.Errorf("invalid token")
+		}
+		return "jane@example.com", nil
+	}
+
+	orchestrator := saga.NewOrchestrator(adminDefinition{}, store, nil)
+	handler := edathttp.SagaAdminHandler(store,
+		edathttp.WithSagaAdminOrchestrators(orchestrator),
+		edathttp.WithSagaAdminAuthenticator(authenticator),
+	)
+
+	// the operator given in the body is not trusted
+	const body = `{"operator": "mallory@example.com", "reason": "carrier outage"}`
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/instances/shipping/saga-1/fail", strings.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %v, want %v: %s", rec.Code, http.StatusUnauthorized, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/shipping/saga-1/fail", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, want %v: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var got struct {
+		Interventions []struct {
+			Operator string `json:"operator"`
+		} `json:"interventions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(got.Interventions) != 1 || got.Interventions[0].Operator != "jane@example.com" {
+		t.Errorf("interventions = %+v, want operator jane@example.com", got.Interventions)
+	}
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook signatures
+const (
+	SignatureHeader = "X-Edat-Signature"
+	TimestampHeader = "X-Edat-Timestamp"
+	SignaturePrefix = "sha256="
+)
+
+// ChannelHeader is the header the channel of a message is sent in. It is part of the signature, and is used by the
+// Consumer in place of the request path so that messages may be sent to any URL
+const ChannelHeader = "X-Edat-Channel"
+
+// Sign returns the signature of a request as it is sent in the SignatureHeader
+//
+// The signature is the hex encoded HMAC-SHA256 using the shared secret of the timestamp sent in the TimestampHeader,
+// the channel, and the body, joined by periods. Signing the channel and timestamp prevents a signed body from being
+// replayed into other channels or after the tolerance of the consumer has passed
+func Sign(secret []byte, timestamp, channel string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "." + channel + ".")) // nolint:errcheck
+	mac.Write(body)                                    // nolint:errcheck
+
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the signature is a valid signature of the request for the shared secret
+func VerifySignature(secret []byte, timestamp, channel string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, channel, body)))
+}
+
+// SignatureTimestamp formats the time as it is sent in the TimestampHeader
+func SignatureTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// withinTolerance reports whether the timestamp is no further from now than the tolerance
+func withinTolerance(timestamp string, now time.Time, tolerance time.Duration) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age < 0 {
+		age = -age
+	}
+
+	return age <= tolerance
+}
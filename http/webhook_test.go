@@ -0,0 +1,293 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/cloudevents"
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	edathttp "github.com/stackus/edat/http"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/retry"
+)
+
+var testSecret = []byte("secret")
+
+func testRetryer() retry.Retryer {
+	return retry.NewConstantBackoff(retry.WithBackoffInitialInterval(time.Millisecond), retry.WithBackoffMaxRetries(3))
+}
+
+func listen(t *testing.T, consumer *edathttp.Consumer, channel string, fn msg.ReceiveMessageFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = consumer.Listen(ctx, channel, fn)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// give the listener time to register
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestWebhook_SendReceive(t *testing.T) {
+	consumer := edathttp.NewConsumer(edathttp.WithConsumerSecret(testSecret))
+	mux := http.NewServeMux()
+	mux.Handle("/webhooks/", http.StripPrefix("/webhooks", consumer))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	received := make(chan msg.Message, 1)
+	listen(t, consumer, "orders.events", func(ctx context.Context, message msg.Message) error {
+		received <- message
+		return nil
+	})
+
+	producer := edathttp.NewProducer(server.URL+"/webhooks/", edathttp.WithProducerSecret(testSecret), edathttp.WithProducerRetryer(testRetryer()))
+
+	message := msg.NewMessage([]byte(`{"OrderID":"order-id"}`), msg.WithHeaders(map[string]string{
+		msg.MessageEventName:     "orders.OrderCreated",
+		msg.MessageCorrelationID: "correlation-id",
+	}))
+
+	err := producer.Send(context.Background(), "orders.events", message)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.ID() != message.ID() {
+			t.Errorf("ID() = %v, want %v", got.ID(), message.ID())
+		}
+		if got.Headers().Get(msg.MessageCorrelationID) != "correlation-id" {
+			t.Errorf("Headers() = %v, want correlation id", got.Headers())
+		}
+		if !bytes.Equal(got.Payload(), message.Payload()) {
+			t.Errorf("Payload() = %s, want %s", got.Payload(), message.Payload())
+		}
+	default:
+		t.Fatalf("message was not received")
+	}
+}
+
+func TestWebhook_ChannelURL(t *testing.T) {
+	consumer := edathttp.NewConsumer(edathttp.WithConsumerSecret(testSecret))
+	mux := http.NewServeMux()
+	mux.Handle("/hooks/", http.StripPrefix("/hooks", consumer))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	received := make(chan msg.Message, 1)
+	listen(t, consumer, "orders.events", func(ctx context.Context, message msg.Message) error {
+		received <- message
+		return nil
+	})
+
+	producer := edathttp.NewProducer(server.URL+"/webhooks/",
+		edathttp.WithProducerSecret(testSecret),
+		edathttp.WithProducerRetryer(testRetryer()),
+		edathttp.WithProducerChannelURL("orders.events", server.URL+"/hooks/order-webhook"),
+	)
+
+	message := msg.NewMessage([]byte(`{"OrderID":"order-id"}`))
+
+	err := producer.Send(context.Background(), "orders.events", message)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.ID() != message.ID() {
+			t.Errorf("ID() = %v, want %v", got.ID(), message.ID())
+		}
+	default:
+		t.Fatalf("message was not received")
+	}
+}
+
+func TestProducer_Send(t *testing.T) {
+	type want struct {
+		err      bool
+		attempts int32
+	}
+	tests := map[string]struct {
+		statuses []int
+		want     want
+	}{
+		"Success":         {statuses: []int{http.StatusNoContent}, want: want{err: false, attempts: 1}},
+		"RetriedSuccess":  {statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK}, want: want{err: false, attempts: 3}},
+		"RetriedTooMany":  {statuses: []int{http.StatusTooManyRequests, http.StatusNoContent}, want: want{err: false, attempts: 2}},
+		"NotRetried":      {statuses: []int{http.StatusBadRequest}, want: want{err: true, attempts: 1}},
+		"RetriesExceeded": {statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, want: want{err: true, attempts: 3}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := atomic.AddInt32(&attempts, 1)
+				if r.URL.EscapedPath() != "/orders%20events" {
+					t.Errorf("request path = %v, want /orders%%20events", r.URL.EscapedPath())
+				}
+				if r.Header.Get(edathttp.ChannelHeader) != "orders events" {
+					t.Errorf("%s = %v, want orders events", edathttp.ChannelHeader, r.Header.Get(edathttp.ChannelHeader))
+				}
+				if r.Header.Get("Content-Type") != cloudevents.StructuredContentType {
+					t.Errorf("Content-Type = %v, want %v", r.Header.Get("Content-Type"), cloudevents.StructuredContentType)
+				}
+				w.WriteHeader(tt.statuses[attempt-1])
+			}))
+			defer server.Close()
+
+			producer := edathttp.NewProducer(server.URL, edathttp.WithProducerRetryer(testRetryer()))
+
+			err := producer.Send(context.Background(), "orders events", msg.NewMessage([]byte(`{}`)))
+			if (err != nil) != tt.want.err {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.want.err)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.want.attempts {
+				t.Errorf("Send() attempts = %v, want %v", got, tt.want.attempts)
+			}
+		})
+	}
+}
+
+func TestProducer_Tracer(t *testing.T) {
+	traceParents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents <- r.Header.Get(core.TraceParentHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tracer := coretest.NewRecordingTracer()
+	producer := edathttp.NewProducer(server.URL, edathttp.WithProducerTracer(tracer), edathttp.WithProducerRetryer(testRetryer()))
+
+	err := producer.Send(context.Background(), "orders", msg.NewMessage([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("Send() spans = %d, want 1", len(spans))
+	}
+	if spans[0].Kind != core.SpanKindClient || !spans[0].Ended {
+		t.Errorf("Send() span = %+v, want an ended client span", spans[0])
+	}
+	if got := <-traceParents; got != spans[0].Context.TraceParent() {
+		t.Errorf("%s = %v, want %v", core.TraceParentHeader, got, spans[0].Context.TraceParent())
+	}
+}
+
+func TestConsumer_ServeHTTP(t *testing.T) {
+	message := msg.NewMessage([]byte(`{}`))
+	body, err := cloudevents.MarshalStructured(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := edathttp.SignatureTimestamp(time.Now())
+	stale := edathttp.SignatureTimestamp(time.Now().Add(-time.Hour))
+
+	type args struct {
+		method    string
+		path      string
+		channel   string
+		body      []byte
+		timestamp string
+		signature string
+		binary    bool
+	}
+	tests := map[string]struct {
+		secret     []byte
+		args       args
+		receiveErr error
+		want       int
+	}{
+		"Structured":       {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "orders", body)}, want: http.StatusNoContent},
+		"Binary":           {args: args{method: http.MethodPost, path: "/orders", body: []byte(`{}`), binary: true}, want: http.StatusNoContent},
+		"SignedBinary":     {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: []byte(`{}`), timestamp: now, signature: edathttp.Sign(testSecret, now, "orders", []byte(`{}`)), binary: true}, want: http.StatusUnsupportedMediaType},
+		"BadSignature":     {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: now, signature: edathttp.Sign([]byte("wrong"), now, "orders", body)}, want: http.StatusUnauthorized},
+		"MissingSignature": {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: now}, want: http.StatusUnauthorized},
+		"OtherChannel":     {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "payments", body)}, want: http.StatusUnauthorized},
+		"StaleTimestamp":   {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: stale, signature: edathttp.Sign(testSecret, stale, "orders", body)}, want: http.StatusUnauthorized},
+		"MissingTimestamp": {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, signature: edathttp.Sign(testSecret, "", "orders", body)}, want: http.StatusUnauthorized},
+		"ChannelHeader":    {secret: testSecret, args: args{method: http.MethodPost, path: "/order-webhook", channel: "orders", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "orders", body)}, want: http.StatusNoContent},
+		"PathSignature":    {secret: testSecret, args: args{method: http.MethodPost, path: "/order-webhook", channel: "orders", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "order-webhook", body)}, want: http.StatusUnauthorized},
+		"UnknownChannel":   {secret: testSecret, args: args{method: http.MethodPost, path: "/payments", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "payments", body)}, want: http.StatusNotFound},
+		"WrongMethod":      {secret: testSecret, args: args{method: http.MethodGet, path: "/orders"}, want: http.StatusMethodNotAllowed},
+		"InvalidEvent":     {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: []byte(`{}`), timestamp: now, signature: edathttp.Sign(testSecret, now, "orders", []byte(`{}`))}, want: http.StatusBadRequest},
+		"ReceiverError":    {secret: testSecret, args: args{method: http.MethodPost, path: "/orders", body: body, timestamp: now, signature: edathttp.Sign(testSecret, now, "orders", body)}, receiveErr: errors.New("receiver error"), want: http.StatusInternalServerError},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			consumer := edathttp.NewConsumer(edathttp.WithConsumerSecret(tt.secret))
+			listen(t, consumer, "orders", func(ctx context.Context, m msg.Message) error {
+				if m.ID() != message.ID() {
+					t.Errorf("ID() = %v, want %v", m.ID(), message.ID())
+				}
+				return tt.receiveErr
+			})
+
+			req := httptest.NewRequest(tt.args.method, tt.args.path, bytes.NewReader(tt.args.body))
+			if tt.args.binary {
+				if err := cloudevents.WriteBinary(message, req.Header); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				req.Header.Set("Content-Type", cloudevents.StructuredContentType)
+			}
+			if tt.args.channel != "" {
+				req.Header.Set(edathttp.ChannelHeader, tt.args.channel)
+			}
+			if tt.args.timestamp != "" {
+				req.Header.Set(edathttp.TimestampHeader, tt.args.timestamp)
+			}
+			if tt.args.signature != "" {
+				req.Header.Set(edathttp.SignatureHeader, tt.args.signature)
+			}
+
+			rec := httptest.NewRecorder()
+			consumer.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("ServeHTTP() status = %v, want %v", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsumer_Close(t *testing.T) {
+	consumer := edathttp.NewConsumer()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Listen(context.Background(), "orders", func(context.Context, msg.Message) error { return nil })
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	if err := consumer.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Listen() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Listen() did not return after Close()")
+	}
+}
@@ -9,6 +9,7 @@ import (
 )
 
 var channels = sync.Map{}
+var patterns = sync.Map{}
 
 // Consumer implements msg.Consumer
 type Consumer struct {
@@ -16,6 +17,7 @@ type Consumer struct {
 }
 
 var _ msg.Consumer = (*Consumer)(nil)
+var _ msg.PatternConsumer = (*Consumer)(nil)
 
 // NewConsumer constructs a new Consumer
 func NewConsumer(options ...ConsumerOption) *Consumer {
@@ -34,8 +36,17 @@ func NewConsumer(options ...ConsumerOption) *Consumer {
 func (c *Consumer) Listen(ctx context.Context, channel string, consumer msg.ReceiveMessageFunc) error {
 	result, _ := channels.LoadOrStore(channel, make(chan msg.Message))
 
-	messages := result.(chan msg.Message)
+	return c.listen(ctx, result.(chan msg.Message), consumer)
+}
+
+// ListenPattern implements msg.PatternConsumer.ListenPattern
+func (c *Consumer) ListenPattern(ctx context.Context, pattern string, consumer msg.ReceiveMessageFunc) error {
+	result, _ := patterns.LoadOrStore(pattern, make(chan msg.Message))
+
+	return c.listen(ctx, result.(chan msg.Message), consumer)
+}
 
+func (c *Consumer) listen(ctx context.Context, messages chan msg.Message, consumer msg.ReceiveMessageFunc) error {
 	for {
 		select {
 		case message, ok := <-messages:
@@ -63,6 +74,15 @@ func (c *Consumer) Close(context.Context) error {
 		return true
 	})
 
+	patterns.Range(func(key, value interface{}) bool {
+		messages := value.(chan msg.Message)
+		close(messages)
+
+		c.logger.Trace("closed channel pattern", log.String("Pattern", key.(string)))
+
+		return true
+	})
+
 	c.logger.Trace("closing message source")
 	return nil
 }
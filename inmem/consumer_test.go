@@ -0,0 +1,59 @@
+package inmem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+)
+
+func TestConsumer_ListenPattern(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	consumer := inmem.NewConsumer()
+	producer := inmem.NewProducer()
+
+	received := make(chan msg.Message, 10)
+	go func() {
+		_ = consumer.ListenPattern(ctx, "pattern_test.orders.*", func(_ context.Context, message msg.Message) error {
+			received <- message
+			return nil
+		})
+	}()
+
+	customerMsg := msg.NewMessage([]byte(`{}`))
+	orderMsg := msg.NewMessage([]byte(`{}`))
+
+	// messages sent before the pattern is being listened to are dropped; keep sending until one arrives
+	var got msg.Message
+	for got == nil {
+		if err := producer.Send(ctx, "pattern_test.customers.created", customerMsg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if err := producer.Send(ctx, "pattern_test.orders.created", orderMsg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		select {
+		case got = <-received:
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the pattern to receive a message")
+		}
+	}
+
+	if got.ID() != orderMsg.ID() {
+		t.Errorf("ListenPattern() received %s, want %s", got.ID(), orderMsg.ID())
+	}
+
+	select {
+	case message := <-received:
+		if message.ID() != orderMsg.ID() {
+			t.Errorf("ListenPattern() received %s from a channel that does not match", message.ID())
+		}
+	default:
+	}
+}
@@ -13,6 +13,7 @@ type Producer struct {
 }
 
 var _ msg.Producer = (*Producer)(nil)
+var _ msg.BatchProducer = (*Producer)(nil)
 
 // NewProducer constructs a new Producer
 func NewProducer(options ...ProducerOption) *Producer {
@@ -37,6 +38,30 @@ func (p *Producer) Send(_ context.Context, channel string, message msg.Message)
 		p.logger.Trace("message sent to inmem channel", log.String("Channel", channel))
 	}
 
+	patterns.Range(func(key, value interface{}) bool {
+		if pattern := key.(string); msg.MatchChannel(pattern, channel) {
+			destination := value.(chan msg.Message)
+
+			destination <- message
+
+			p.logger.Trace("message sent to inmem channel pattern", log.String("Channel", channel), log.String("Pattern", pattern))
+		}
+
+		return true
+	})
+
+	return nil
+}
+
+// SendBatch implements msg.BatchProducer.SendBatch
+func (p *Producer) SendBatch(ctx context.Context, channel string, messages []msg.Message) error {
+	for _, message := range messages {
+		err := p.Send(ctx, channel, message)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
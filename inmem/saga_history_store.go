@@ -0,0 +1,49 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/stackus/edat/saga"
+)
+
+// SagaHistoryStore implements saga.HistoryStore
+type SagaHistoryStore struct {
+	records map[string][]saga.HistoryRecord
+	mu      sync.Mutex
+}
+
+var _ saga.HistoryStore = (*SagaHistoryStore)(nil)
+
+// NewSagaHistoryStore constructs a new SagaHistoryStore
+func NewSagaHistoryStore() *SagaHistoryStore {
+	return &SagaHistoryStore{
+		records: map[string][]saga.HistoryRecord{},
+	}
+}
+
+// Record implements saga.HistoryStore.Record
+func (s *SagaHistoryStore) Record(_ context.Context, records ...saga.HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		instanceID := s.instanceID(record.SagaName, record.SagaID)
+		s.records[instanceID] = append(s.records[instanceID], record)
+	}
+
+	return nil
+}
+
+// History implements saga.HistoryStore.History
+func (s *SagaHistoryStore) History(_ context.Context, sagaName, sagaID string) ([]saga.HistoryRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]saga.HistoryRecord{}, s.records[s.instanceID(sagaName, sagaID)]...), nil
+}
+
+func (s *SagaHistoryStore) instanceID(sagaName, sagaID string) string {
+	return fmt.Sprintf("%s:%s", sagaName, sagaID)
+}
@@ -3,27 +3,47 @@ package inmem
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/saga"
 )
 
 // SagaInstanceStore implements saga.InstanceStore
+//
+// Updates are rejected with a saga.InstanceConflictError when the instance version is stale. Saga data is copied
+// when it is saved and found so that changes to the data of an instance are not seen until it has been saved; the
+// maps, slices, and pointers held by unexported fields of the data are not copied
 type SagaInstanceStore struct {
 	instances sync.Map
+	mu        sync.Mutex
 }
 
 type instanceData struct {
-	sagaID       string
-	sagaName     string
-	sagaData     core.SagaData
-	currentStep  int
-	endState     bool
-	compensating bool
+	sagaID         string
+	sagaName       string
+	sagaData       core.SagaData
+	currentStep    int
+	endState       bool
+	compensating   bool
+	failed         bool
+	retries        int
+	branches       map[int]saga.BranchStatus
+	stepID         string
+	deadline       time.Time
+	correlationKey string
+	version        int
+	createdAt      time.Time
+	updatedAt      time.Time
+	interventions  []saga.Intervention
 }
 
 var _ saga.InstanceStore = (*SagaInstanceStore)(nil)
+var _ saga.InstanceQuerier = (*SagaInstanceStore)(nil)
+var _ saga.InstanceCorrelator = (*SagaInstanceStore)(nil)
 
 // NewSagaInstanceStore constructs a new SagaInstanceStore
 func NewSagaInstanceStore() *SagaInstanceStore {
@@ -35,11 +55,7 @@ func NewSagaInstanceStore() *SagaInstanceStore {
 // Find implements saga.InstanceStore.Find
 func (s *SagaInstanceStore) Find(_ context.Context, sagaName, sagaID string) (*saga.Instance, error) {
 	if dataT, exists := s.instances.Load(s.instanceID(sagaName, sagaID)); exists {
-		data := dataT.(instanceData)
-
-		instance := saga.NewSagaInstance(data.sagaName, sagaID, data.sagaData, data.currentStep, data.endState, data.compensating)
-
-		return instance, nil
+		return dataT.(instanceData).instance(), nil
 	}
 
 	return nil, nil
@@ -47,24 +63,126 @@ func (s *SagaInstanceStore) Find(_ context.Context, sagaName, sagaID string) (*s
 
 // Save implements saga.InstanceStore.Save
 func (s *SagaInstanceStore) Save(_ context.Context, instance *saga.Instance) error {
-	return s.save(instance)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save(instance, instance.Version())
 }
 
 // Update implements saga.InstanceStore.Update
 func (s *SagaInstanceStore) Update(_ context.Context, instance *saga.Instance) error {
-	return s.save(instance)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dataT, exists := s.instances.Load(s.instanceID(instance.SagaName(), instance.SagaID())); exists {
+		if version := dataT.(instanceData).version; version != instance.Version() {
+			return &saga.InstanceConflictError{
+				SagaName:      instance.SagaName(),
+				SagaID:        instance.SagaID(),
+				Version:       instance.Version(),
+				StoredVersion: version,
+			}
+		}
+	}
+
+	return s.save(instance, instance.Version()+1)
+}
+
+// FindByCorrelationKey implements saga.InstanceCorrelator.FindByCorrelationKey
+func (s *SagaInstanceStore) FindByCorrelationKey(_ context.Context, sagaName, correlationKey string) ([]*saga.Instance, error) {
+	var instances []*saga.Instance
+
+	if correlationKey == "" {
+		return nil, nil
+	}
+
+	s.instances.Range(func(_, value interface{}) bool {
+		data := value.(instanceData)
+		if data.sagaName != sagaName || data.correlationKey != correlationKey || data.endState || data.failed {
+			return true
+		}
+		instances = append(instances, data.instance())
+		return true
+	})
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].CreatedAt().Before(instances[j].CreatedAt())
+	})
+
+	return instances, nil
 }
 
-func (s *SagaInstanceStore) save(instance *saga.Instance) error {
+// Query implements saga.InstanceQuerier.Query
+func (s *SagaInstanceStore) Query(_ context.Context, query saga.InstanceQuery) (saga.InstancePage, error) {
+	var instances []*saga.Instance
+
+	s.instances.Range(func(_, value interface{}) bool {
+		if instance := value.(instanceData).instance(); query.Matches(instance) {
+			instances = append(instances, instance)
+		}
+		return true
+	})
+
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].CreatedAt().Equal(instances[j].CreatedAt()) {
+			return instances[i].SagaID() < instances[j].SagaID()
+		}
+		return instances[i].CreatedAt().Before(instances[j].CreatedAt())
+	})
+
+	page := saga.InstancePage{Total: len(instances)}
+
+	if query.Offset >= len(instances) {
+		return page, nil
+	}
+
+	instances = instances[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(instances) {
+		instances = instances[:query.Limit]
+	}
+
+	page.Instances = instances
+
+	return page, nil
+}
+
+// Counts implements saga.InstanceQuerier.Counts
+func (s *SagaInstanceStore) Counts(_ context.Context, sagaName string) (map[saga.InstanceState]int, error) {
+	counts := make(map[saga.InstanceState]int, len(saga.InstanceStates))
+	for _, state := range saga.InstanceStates {
+		counts[state] = 0
+	}
+
+	s.instances.Range(func(_, value interface{}) bool {
+		if instance := value.(instanceData).instance(); sagaName == "" || instance.SagaName() == sagaName {
+			counts[instance.State()]++
+		}
+		return true
+	})
+
+	return counts, nil
+}
+
+func (s *SagaInstanceStore) save(instance *saga.Instance, version int) error {
 	instanceID := s.instanceID(instance.SagaName(), instance.SagaID())
 
 	s.instances.Store(instanceID, instanceData{
-		sagaID:       instance.SagaID(),
-		sagaName:     instance.SagaName(),
-		sagaData:     instance.SagaData(),
-		currentStep:  instance.CurrentStep(),
-		endState:     instance.EndState(),
-		compensating: instance.Compensating(),
+		sagaID:         instance.SagaID(),
+		sagaName:       instance.SagaName(),
+		sagaData:       copySagaData(instance.SagaData()),
+		currentStep:    instance.CurrentStep(),
+		endState:       instance.EndState(),
+		compensating:   instance.Compensating(),
+		failed:         instance.Failed(),
+		retries:        instance.Retries(),
+		branches:       instance.Branches(),
+		stepID:         instance.StepID(),
+		deadline:       instance.Deadline(),
+		correlationKey: instance.CorrelationKey(),
+		version:        version,
+		createdAt:      instance.CreatedAt(),
+		updatedAt:      instance.UpdatedAt(),
+		interventions:  instance.Interventions(),
 	})
 
 	return nil
@@ -73,3 +191,83 @@ func (s *SagaInstanceStore) save(instance *saga.Instance) error {
 func (s *SagaInstanceStore) instanceID(sagaName, sagaID string) string {
 	return fmt.Sprintf("%s:%s", sagaName, sagaID)
 }
+
+func (d instanceData) instance() *saga.Instance {
+	return saga.NewSagaInstance(d.sagaName, d.sagaID, copySagaData(d.sagaData), d.currentStep, d.endState, d.compensating,
+		saga.WithInstanceFailed(d.failed),
+		saga.WithInstanceRetries(d.retries),
+		saga.WithInstanceBranches(d.branches),
+		saga.WithInstanceStepID(d.stepID),
+		saga.WithInstanceDeadline(d.deadline),
+		saga.WithInstanceCorrelationKey(d.correlationKey),
+		saga.WithInstanceVersion(d.version),
+		saga.WithInstanceCreatedAt(d.createdAt),
+		saga.WithInstanceUpdatedAt(d.updatedAt),
+		saga.WithInstanceInterventions(d.interventions),
+	)
+}
+
+// copySagaData returns a deep copy of the saga data without requiring the data type to be registered
+func copySagaData(sagaData core.SagaData) core.SagaData {
+	if sagaData == nil {
+		return nil
+	}
+
+	return copyValue(reflect.ValueOf(sagaData)).Interface().(core.SagaData)
+}
+
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(copyValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		// unexported fields cannot be set by reflection and are copied along with the struct
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return c
+	default:
+		return v
+	}
+}
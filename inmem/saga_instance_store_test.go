@@ -0,0 +1,95 @@
+package inmem_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/saga"
+)
+
+type orderData struct {
+	Items  []string
+	Totals map[string]int
+	Note   *string
+}
+
+func (orderData) SagaDataName() string { return "inmem_test.orderData" }
+
+func TestSagaInstanceStore_Update(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewSagaInstanceStore()
+
+	err := store.Save(ctx, saga.NewSagaInstance("orders", "saga-1", nil, 0, false, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := store.Find(ctx, "orders", "saga-1")
+	second, _ := store.Find(ctx, "orders", "saga-1")
+
+	if err = store.Update(ctx, first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	err = store.Update(ctx, second)
+
+	var conflict *saga.InstanceConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, saga.ErrInstanceConflict) {
+		t.Fatalf("Update() error = %v, want %v", err, saga.ErrInstanceConflict)
+	}
+	if conflict.Version != 0 || conflict.StoredVersion != 1 {
+		t.Errorf("conflict versions = %d, %d, want 0, 1", conflict.Version, conflict.StoredVersion)
+	}
+
+	found, _ := store.Find(ctx, "orders", "saga-1")
+	if found.Version() != 1 {
+		t.Fatalf("Version() = %d, want 1", found.Version())
+	}
+
+	if err = store.Update(ctx, found); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+}
+
+func TestSagaInstanceStore_SagaDataCopy(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewSagaInstanceStore()
+
+	note := "gift"
+	data := &orderData{Items: []string{"book"}, Totals: map[string]int{"book": 1}, Note: &note}
+
+	// the saga data type is not registered with core.RegisterSagaData
+	err := store.Save(ctx, saga.NewSagaInstance("orders", "saga-1", data, 0, false, false))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data.Items[0] = "pen"
+	data.Totals["book"] = 2
+	note = "none"
+
+	found, err := store.Find(ctx, "orders", "saga-1")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	want := &orderData{Items: []string{"book"}, Totals: map[string]int{"book": 1}}
+	got := found.SagaData().(*orderData)
+	if got.Note == nil || *got.Note != "gift" {
+		t.Errorf("Note = %v, want gift", got.Note)
+	}
+	got.Note = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SagaData() = %+v, want %+v", got, want)
+	}
+
+	got.Items[0] = "pen"
+
+	again, _ := store.Find(ctx, "orders", "saga-1")
+	if item := again.SagaData().(*orderData).Items[0]; item != "book" {
+		t.Errorf("Items[0] = %v, want book", item)
+	}
+}
@@ -0,0 +1,83 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stackus/edat/msg"
+)
+
+// ScheduledMessageStore implements msg.ScheduledMessageStore
+type ScheduledMessageStore struct {
+	messages map[string]scheduledMsg
+	mu       sync.Mutex
+}
+
+type scheduledMsg struct {
+	message   msg.Message
+	deliverAt time.Time
+}
+
+var _ msg.ScheduledMessageStore = (*ScheduledMessageStore)(nil)
+
+// NewScheduledMessageStore constructs a new ScheduledMessageStore
+func NewScheduledMessageStore() *ScheduledMessageStore {
+	return &ScheduledMessageStore{
+		messages: make(map[string]scheduledMsg),
+	}
+}
+
+// Schedule implements msg.ScheduledMessageStore.Schedule
+func (s *ScheduledMessageStore) Schedule(_ context.Context, message msg.Message, deliverAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[message.ID()] = scheduledMsg{
+		message:   message,
+		deliverAt: deliverAt,
+	}
+
+	return nil
+}
+
+// FetchDue implements msg.ScheduledMessageStore.FetchDue
+func (s *ScheduledMessageStore) FetchDue(_ context.Context, dueBy time.Time, limit int) ([]msg.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]scheduledMsg, 0)
+	for _, message := range s.messages {
+		if !message.deliverAt.After(dueBy) {
+			due = append(due, message)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].deliverAt.Before(due[j].deliverAt)
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	messages := make([]msg.Message, 0, len(due))
+	for _, message := range due {
+		messages = append(messages, message.message)
+	}
+
+	return messages, nil
+}
+
+// Remove implements msg.ScheduledMessageStore.Remove
+func (s *ScheduledMessageStore) Remove(_ context.Context, messageIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range messageIDs {
+		delete(s.messages, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package metrics
+
+// Metric names of the instrumented components
+const (
+	MessagesPublished       = "edat_messages_published_total"
+	MessagePublishErrors    = "edat_message_publish_errors_total"
+	MessagePublishDuration  = "edat_message_publish_duration_seconds"
+	MessagesReceived        = "edat_messages_received_total"
+	MessageReceiveErrors    = "edat_message_receive_errors_total"
+	MessageReceiveDuration  = "edat_message_receive_duration_seconds"
+	MessagesHandled         = "edat_messages_handled_total"
+	MessageHandlerErrors    = "edat_message_handler_errors_total"
+	MessageHandlerDuration  = "edat_message_handler_duration_seconds"
+	OutboxMessagesProcessed = "edat_outbox_messages_processed_total"
+	OutboxProcessErrors     = "edat_outbox_process_errors_total"
+	OutboxLag               = "edat_outbox_lag_seconds"
+	SagasStarted            = "edat_sagas_started_total"
+	SagasCompleted          = "edat_sagas_completed_total"
+	SagasCompensated        = "edat_sagas_compensated_total"
+	SagasActive             = "edat_sagas_active"
+)
@@ -0,0 +1,299 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type of metric
+type Type int
+
+// Metric types
+const (
+	CounterType Type = iota
+	GaugeType
+	HistogramType
+)
+
+func (t Type) String() string {
+	switch t {
+	case CounterType:
+		return "counter"
+	case GaugeType:
+		return "gauge"
+	case HistogramType:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// Family is a snapshot of every series of a metric
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Sample is a snapshot of a single series of a metric
+//
+// Count, Sum and Buckets are only set for histograms
+type Sample struct {
+	Labels  Labels
+	Value   float64
+	Count   uint64
+	Sum     float64
+	Buckets []Bucket
+}
+
+// Bucket is a cumulative histogram bucket
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Gatherer is implemented by registries that are able to snapshot their metrics
+type Gatherer interface {
+	Gather() []Family
+}
+
+// MemoryRegistry is a Registry that keeps its metrics in memory
+//
+// The metrics may be exposed using the http.MetricsHandler
+type MemoryRegistry struct {
+	families map[string]*family
+	mu       sync.Mutex
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    Type
+	buckets []float64
+	series  map[string]*series
+	mu      sync.Mutex
+}
+
+type series struct {
+	labels  Labels
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+var _ Registry = (*MemoryRegistry)(nil)
+var _ Gatherer = (*MemoryRegistry)(nil)
+
+// NewMemoryRegistry constructs a new MemoryRegistry
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		families: map[string]*family{},
+	}
+}
+
+// Counter implements Registry.Counter
+func (r *MemoryRegistry) Counter(name, help string) Counter {
+	return counter{r.family(name, help, CounterType, nil)}
+}
+
+// Gauge implements Registry.Gauge
+func (r *MemoryRegistry) Gauge(name, help string) Gauge {
+	return gauge{r.family(name, help, GaugeType, nil)}
+}
+
+// Histogram implements Registry.Histogram
+//
+// DefaultBuckets will be used when no buckets are provided
+func (r *MemoryRegistry) Histogram(name, help string, buckets []float64) Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	return histogram{r.family(name, help, HistogramType, sorted)}
+}
+
+// Gather implements Gatherer.Gather
+//
+// Families are sorted by name and samples by their labels
+func (r *MemoryRegistry) Gather() []Family {
+	r.mu.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	snapshot := make([]Family, 0, len(families))
+	for _, f := range families {
+		snapshot = append(snapshot, f.gather())
+	}
+
+	return snapshot
+}
+
+func (r *MemoryRegistry) family(name, help string, kind Type, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, exists := r.families[name]; exists {
+		if f.kind != kind {
+			panic(fmt.Sprintf("metric `%s` is already registered as a %s", name, f.kind))
+		}
+		return f
+	}
+
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+
+	r.families[name] = f
+
+	return f
+}
+
+func (f *family) with(labels Labels, fn func(s *series)) {
+	key := labelsKey(labels)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, exists := f.series[key]
+	if !exists {
+		s = &series{labels: copyLabels(labels)}
+		if f.kind == HistogramType {
+			s.buckets = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+
+	fn(s)
+}
+
+func (f *family) gather() Family {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	samples := make([]Sample, 0, len(keys))
+	for _, key := range keys {
+		s := f.series[key]
+		sample := Sample{
+			Labels: copyLabels(s.labels),
+			Value:  s.value,
+			Count:  s.count,
+			Sum:    s.sum,
+		}
+
+		if f.kind == HistogramType {
+			var cumulative uint64
+			for i, upperBound := range f.buckets {
+				cumulative += s.buckets[i]
+				sample.Buckets = append(sample.Buckets, Bucket{UpperBound: upperBound, Count: cumulative})
+			}
+			sample.Buckets = append(sample.Buckets, Bucket{UpperBound: math.Inf(1), Count: s.count})
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return Family{
+		Name:    f.name,
+		Help:    f.help,
+		Type:    f.kind,
+		Samples: samples,
+	}
+}
+
+type counter struct{ f *family }
+
+func (c counter) Inc(labels Labels) {
+	c.Add(1, labels)
+}
+
+func (c counter) Add(value float64, labels Labels) {
+	// counters only ever increase
+	if value < 0 {
+		return
+	}
+
+	c.f.with(labels, func(s *series) {
+		s.value += value
+	})
+}
+
+type gauge struct{ f *family }
+
+func (g gauge) Set(value float64, labels Labels) {
+	g.f.with(labels, func(s *series) {
+		s.value = value
+	})
+}
+
+func (g gauge) Add(value float64, labels Labels) {
+	g.f.with(labels, func(s *series) {
+		s.value += value
+	})
+}
+
+type histogram struct{ f *family }
+
+func (h histogram) Observe(value float64, labels Labels) {
+	h.f.with(labels, func(s *series) {
+		s.count++
+		s.sum += value
+		for i, upperBound := range h.f.buckets {
+			if value <= upperBound {
+				s.buckets[i]++
+				break
+			}
+		}
+	})
+}
+
+func labelsKey(labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(labels[name])
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+func copyLabels(labels Labels) Labels {
+	c := make(Labels, len(labels))
+	for name, value := range labels {
+		c[name] = value
+	}
+
+	return c
+}
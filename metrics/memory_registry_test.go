@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stackus/edat/metrics"
+)
+
+func TestMemoryRegistry_Gather(t *testing.T) {
+	tests := map[string]struct {
+		record func(r *metrics.MemoryRegistry)
+		want   []metrics.Family
+	}{
+		"Counter": {
+			record: func(r *metrics.MemoryRegistry) {
+				c := r.Counter("counter", "help")
+				c.Inc(metrics.Labels{"channel": "b"})
+				c.Add(2, metrics.Labels{"channel": "a"})
+				c.Add(-1, metrics.Labels{"channel": "a"})
+				r.Counter("counter", "help").Inc(metrics.Labels{"channel": "a"})
+			},
+			want: []metrics.Family{
+				{
+					Name: "counter",
+					Help: "help",
+					Type: metrics.CounterType,
+					Samples: []metrics.Sample{
+						{Labels: metrics.Labels{"channel": "a"}, Value: 3},
+						{Labels: metrics.Labels{"channel": "b"}, Value: 1},
+					},
+				},
+			},
+		},
+		"Gauge": {
+			record: func(r *metrics.MemoryRegistry) {
+				g := r.Gauge("gauge", "help")
+				g.Set(5, nil)
+				g.Add(-2, nil)
+			},
+			want: []metrics.Family{
+				{
+					Name:    "gauge",
+					Help:    "help",
+					Type:    metrics.GaugeType,
+					Samples: []metrics.Sample{{Labels: metrics.Labels{}, Value: 3}},
+				},
+			},
+		},
+		"Histogram": {
+			record: func(r *metrics.MemoryRegistry) {
+				h := r.Histogram("histogram", "help", []float64{1, 0.5})
+				h.Observe(0.25, nil)
+				h.Observe(0.75, nil)
+				h.Observe(2, nil)
+			},
+			want: []metrics.Family{
+				{
+					Name: "histogram",
+					Help: "help",
+					Type: metrics.HistogramType,
+					Samples: []metrics.Sample{
+						{
+							Labels: metrics.Labels{},
+							Count:  3,
+							Sum:    3,
+							Buckets: []metrics.Bucket{
+								{UpperBound: 0.5, Count: 1},
+								{UpperBound: 1, Count: 2},
+								{UpperBound: math.Inf(1), Count: 3},
+							},
+						},
+					},
+				},
+			},
+		},
+		"SortedByName": {
+			record: func(r *metrics.MemoryRegistry) {
+				r.Gauge("b", "")
+				r.Counter("a", "")
+			},
+			want: []metrics.Family{
+				{Name: "a", Type: metrics.CounterType, Samples: []metrics.Sample{}},
+				{Name: "b", Type: metrics.GaugeType, Samples: []metrics.Sample{}},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := metrics.NewMemoryRegistry()
+			tt.record(r)
+			if got := r.Gather(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Gather() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRegistry_TypeConflict(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Gauge() did not panic")
+		}
+	}()
+
+	r := metrics.NewMemoryRegistry()
+	r.Counter("metric", "")
+	r.Gauge("metric", "")
+}
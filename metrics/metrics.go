@@ -0,0 +1,57 @@
+package metrics
+
+// Labels are the label names and values that identify a single series of a metric
+type Labels map[string]string
+
+// Counter is a metric that only ever increases
+type Counter interface {
+	Inc(labels Labels)
+	Add(value float64, labels Labels)
+}
+
+// Gauge is a metric that may be set to any value
+type Gauge interface {
+	Set(value float64, labels Labels)
+	Add(value float64, labels Labels)
+}
+
+// Histogram is a metric that counts observations into buckets
+type Histogram interface {
+	Observe(value float64, labels Labels)
+}
+
+// Registry interface
+//
+// Metrics are identified by name; asking for the same name more than once returns the same metric
+type Registry interface {
+	Counter(name, help string) Counter
+	Gauge(name, help string) Gauge
+	Histogram(name, help string, buckets []float64) Histogram
+}
+
+// DefaultRegistry is set to a Nop registry
+//
+// You may reassign this if you wish to avoid having to pass in With*Metrics(yourRegistry) options
+// into many of the constructors to set a custom registry. It must be reassigned before the
+// instrumented components are constructed
+var DefaultRegistry Registry = NewNopRegistry()
+
+// DefaultBuckets are the histogram buckets, in seconds, used for latencies
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type nopRegistry struct{}
+type nopMetric struct{}
+
+// NewNopRegistry returns a registry of metrics that record nothing
+func NewNopRegistry() Registry {
+	return nopRegistry{}
+}
+
+func (nopRegistry) Counter(string, string) Counter                { return nopMetric{} }
+func (nopRegistry) Gauge(string, string) Gauge                    { return nopMetric{} }
+func (nopRegistry) Histogram(string, string, []float64) Histogram { return nopMetric{} }
+
+func (nopMetric) Inc(Labels)              {}
+func (nopMetric) Add(float64, Labels)     {}
+func (nopMetric) Set(float64, Labels)     {}
+func (nopMetric) Observe(float64, Labels) {}
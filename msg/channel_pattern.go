@@ -0,0 +1,25 @@
+package msg
+
+import (
+	"path"
+	"strings"
+)
+
+// IsChannelPattern returns whether or not the channel is a pattern of channel names
+//
+// Patterns use the syntax of path.Match, e.g. "orders.*" or "*"
+func IsChannelPattern(channel string) bool {
+	return strings.ContainsAny(channel, "*?[")
+}
+
+// MatchChannel returns whether or not the channel name is matched by the pattern
+//
+// Malformed patterns do not match any channel
+func MatchChannel(pattern, channel string) bool {
+	matched, err := path.Match(pattern, channel)
+	if err != nil {
+		return false
+	}
+
+	return matched
+}
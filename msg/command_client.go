@@ -0,0 +1,140 @@
+package msg
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/log"
+)
+
+// CommandClient sends commands and waits for their replies
+//
+// The client must also be subscribed to its private reply channel to receive replies
+//  client := msg.NewCommandClient(publisher)
+//  subscriber.Subscribe(client.ReplyChannel(), client)
+type CommandClient struct {
+	publisher    CommandMessagePublisher
+	replyChannel string
+	pending      map[string]chan Reply
+	logger       log.Logger
+	mu           sync.Mutex
+}
+
+var _ MessageReceiver = (*CommandClient)(nil)
+
+// NewCommandClient constructs a new CommandClient
+func NewCommandClient(publisher CommandMessagePublisher, options ...CommandClientOption) *CommandClient {
+	c := &CommandClient{
+		publisher:    publisher,
+		replyChannel: "edat.msg.CommandClient." + uuid.New().String(),
+		pending:      map[string]chan Reply{},
+		logger:       log.DefaultLogger,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.logger.Trace("msg.CommandClient constructed", log.String("ReplyChannel", c.replyChannel))
+
+	return c
+}
+
+// ReplyChannel returns the channel replies are to be received from msg.Subscribers
+func (c *CommandClient) ReplyChannel() string {
+	return c.replyChannel
+}
+
+// Send publishes the command and waits for the reply to arrive or for the context to be done
+func (c *CommandClient) Send(ctx context.Context, command core.Command, options ...MessageOption) (Reply, error) {
+	clientID := uuid.New().String()
+
+	logger := c.logger.Sub(
+		log.String("CommandName", command.CommandName()),
+		log.String("ClientID", clientID),
+	)
+
+	replies := make(chan Reply, 1)
+
+	c.mu.Lock()
+	c.pending[clientID] = replies
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, clientID)
+		c.mu.Unlock()
+	}()
+
+	msgOptions := append([]MessageOption{}, options...)
+	msgOptions = append(msgOptions, WithHeaders(map[string]string{
+		MessageCommandClientID: clientID,
+	}))
+
+	logger.Trace("sending command")
+
+	err := c.publisher.PublishCommand(ctx, c.replyChannel, command, msgOptions...)
+	if err != nil {
+		logger.Error("error sending command", log.Error(err))
+		return nil, err
+	}
+
+	select {
+	case reply := <-replies:
+		logger.Trace("reply received")
+		return reply, nil
+	case <-ctx.Done():
+		logger.Warn("timed out waiting for reply")
+		return nil, ctx.Err()
+	}
+}
+
+// ReceiveMessage implements MessageReceiver.ReceiveMessage
+func (c *CommandClient) ReceiveMessage(_ context.Context, message Message) error {
+	replyName, err := message.Headers().GetRequired(MessageReplyName)
+	if err != nil {
+		c.logger.Error("error reading reply name", log.Error(err))
+		return nil
+	}
+
+	clientID, err := message.Headers().GetRequired(MessageReplyClientID)
+	if err != nil {
+		c.logger.Error("error reading client id", log.Error(err))
+		return nil
+	}
+
+	logger := c.logger.Sub(
+		log.String("ReplyName", replyName),
+		log.String("ClientID", clientID),
+		log.String("MessageID", message.ID()),
+	)
+
+	logger.Debug("received reply message")
+
+	c.mu.Lock()
+	replies, exists := c.pending[clientID]
+	c.mu.Unlock()
+
+	// the sender may have already given up waiting on this reply
+	if !exists {
+		logger.Trace("no pending command for reply")
+		return nil
+	}
+
+	reply, err := core.DeserializeReply(replyName, message.Payload(), message.Headers().Get(MessageContentType))
+	if err != nil {
+		logger.Error("error decoding reply message payload", log.Error(err))
+		return nil
+	}
+
+	select {
+	case replies <- NewReply(reply, message.Headers()):
+	default:
+		logger.Warn("duplicate reply received")
+	}
+
+	return nil
+}
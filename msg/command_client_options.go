@@ -0,0 +1,24 @@
+package msg
+
+import (
+	"github.com/stackus/edat/log"
+)
+
+// CommandClientOption options for CommandClient
+type CommandClientOption func(client *CommandClient)
+
+// WithCommandClientReplyChannel is an option to set the private reply channel of the CommandClient
+//
+// Every running CommandClient must use a channel that is not shared with any other receivers
+func WithCommandClientReplyChannel(replyChannel string) CommandClientOption {
+	return func(client *CommandClient) {
+		client.replyChannel = replyChannel
+	}
+}
+
+// WithCommandClientLogger is an option to set the log.Logger of the CommandClient
+func WithCommandClientLogger(logger log.Logger) CommandClientOption {
+	return func(client *CommandClient) {
+		client.logger = logger
+	}
+}
@@ -0,0 +1,148 @@
+package msg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/msg/msgmocks"
+	"github.com/stackus/edat/msg/msgtest"
+)
+
+func TestCommandClient_Send(t *testing.T) {
+	type args struct {
+		timeout time.Duration
+		command core.Command
+	}
+
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	core.RegisterCommands(msgtest.Command{})
+	msg.RegisterTypes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := msg.NewPublisher(inmem.NewProducer())
+	client := msg.NewCommandClient(publisher)
+
+	// the command value chooses the replies of the dispatcher
+	dispatcher := msg.NewCommandDispatcher(publisher).
+		Handle(msgtest.Command{}, func(_ context.Context, command msg.Command) ([]msg.Reply, error) {
+			switch command.Command().(*msgtest.Command).Value {
+			case "success":
+				return []msg.Reply{msg.WithSuccess()}, nil
+			case "failure":
+				return []msg.Reply{msg.WithFailure()}, nil
+			case "error":
+				return nil, fmt.Errorf("handler-error")
+			default:
+				return nil, nil
+			}
+		})
+
+	subscriber := msg.NewSubscriber(inmem.NewConsumer())
+	subscriber.Subscribe(msgtest.Command{}.DestinationChannel(), dispatcher)
+	subscriber.Subscribe(client.ReplyChannel(), client)
+	go func() {
+		_ = subscriber.Start(ctx)
+	}()
+
+	// commands sent before the channels are being listened to are dropped; keep sending until one is replied to
+	started := time.Now()
+	for {
+		pingCtx, pingCancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		_, err := client.Send(pingCtx, msgtest.Command{Value: "success"})
+		pingCancel()
+		if err == nil {
+			break
+		}
+		if time.Since(started) > time.Second {
+			t.Fatalf("no reply was received from the dispatcher: %v", err)
+		}
+	}
+
+	tests := map[string]struct {
+		args        args
+		wantOutcome string
+		wantErr     bool
+	}{
+		"Success": {
+			args: args{
+				timeout: time.Second,
+				command: msgtest.Command{Value: "success"},
+			},
+			wantOutcome: msg.ReplyOutcomeSuccess,
+			wantErr:     false,
+		},
+		"FailureReply": {
+			args: args{
+				timeout: time.Second,
+				command: msgtest.Command{Value: "failure"},
+			},
+			wantOutcome: msg.ReplyOutcomeFailure,
+			wantErr:     false,
+		},
+		"HandlerError": {
+			args: args{
+				timeout: time.Second,
+				command: msgtest.Command{Value: "error"},
+			},
+			wantOutcome: msg.ReplyOutcomeFailure,
+			wantErr:     false,
+		},
+		"Timeout": {
+			args: args{
+				timeout: 10 * time.Millisecond,
+				command: msgtest.Command{Value: "none"},
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sendCtx, sendCancel := context.WithTimeout(ctx, tt.args.timeout)
+			defer sendCancel()
+			got, err := client.Send(sendCtx, tt.args.command, msg.WithHeaders(map[string]string{
+				msg.MessageCommandPrefix + "ORDER_ID": "order-id",
+			}))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Headers().Get(msg.MessageReplyOutcome) != tt.wantOutcome {
+				t.Errorf("Send() outcome = %v, want %v", got.Headers().Get(msg.MessageReplyOutcome), tt.wantOutcome)
+			}
+			// the command headers are returned by the dispatcher as reply headers
+			if got.Headers().Get(msg.MessageReplyPrefix+"ORDER_ID") != "order-id" {
+				t.Errorf("Send() headers = %v, want correlation headers", got.Headers())
+			}
+		})
+	}
+}
+
+func TestCommandClient_SendPublisherError(t *testing.T) {
+	publisher := msgtest.MockCommandMessagePublisher(func(m *msgmocks.CommandMessagePublisher) {
+		m.On("PublishCommand", mock.Anything, mock.Anything, msgtest.Command{}, mock.Anything).
+			Return(fmt.Errorf("publisher-error"))
+	})
+
+	client := msg.NewCommandClient(publisher, msg.WithCommandClientReplyChannel("reply-channel"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.Send(ctx, msgtest.Command{})
+	if err == nil {
+		t.Errorf("Send() error = nil, wantErr true")
+	}
+	mock.AssertExpectationsForObjects(t, publisher)
+}
@@ -3,9 +3,11 @@ package msg
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // CommandHandlerFunc function handlers for msg.Command
@@ -16,6 +18,8 @@ type CommandDispatcher struct {
 	publisher ReplyMessagePublisher
 	handlers  map[string]CommandHandlerFunc
 	logger    log.Logger
+	registry  metrics.Registry
+	metrics   HandlerMetrics
 }
 
 var _ MessageReceiver = (*CommandDispatcher)(nil)
@@ -26,12 +30,15 @@ func NewCommandDispatcher(publisher ReplyMessagePublisher, options ...CommandDis
 		publisher: publisher,
 		handlers:  map[string]CommandHandlerFunc{},
 		logger:    log.DefaultLogger,
+		registry:  metrics.DefaultRegistry,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
+	c.metrics = NewHandlerMetrics(c.registry)
+
 	c.logger.Trace("msg.CommandDispatcher constructed")
 
 	return c
@@ -68,7 +75,7 @@ func (d *CommandDispatcher) ReceiveMessage(ctx context.Context, message Message)
 
 	logger.Trace("command handler found")
 
-	command, err := core.DeserializeCommand(commandName, message.Payload())
+	command, err := core.DeserializeCommand(commandName, message.Payload(), message.Headers().Get(MessageContentType))
 	if err != nil {
 		logger.Error("error decoding command message payload", log.Error(err))
 		return nil
@@ -82,9 +89,20 @@ func (d *CommandDispatcher) ReceiveMessage(ctx context.Context, message Message)
 
 	correlationHeaders := d.correlationHeaders(message.Headers())
 
+	if IsExpired(message) {
+		logger.Warn("command has expired", log.String("ExpiresAt", message.Headers().Get(MessageExpiresAt)))
+		err = d.sendReplies(ctx, replyChannel, []Reply{WithExpired()}, correlationHeaders)
+		if err != nil {
+			logger.Error("error sending replies", log.Error(err))
+		}
+		return nil
+	}
+
 	cmdMsg := commandMessage{command, correlationHeaders}
 
+	started := time.Now()
 	replies, err := handler(ctx, cmdMsg)
+	d.metrics.Observe("msg.CommandDispatcher", commandName, started, err)
 	if err != nil {
 		logger.Error("command handler returned an error", log.Error(err))
 		rerr := d.sendReplies(ctx, replyChannel, []Reply{WithFailure()}, correlationHeaders)
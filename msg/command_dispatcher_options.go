@@ -2,6 +2,7 @@ package msg
 
 import (
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // CommandDispatcherOption options for CommandDispatcher
@@ -13,3 +14,10 @@ func WithCommandDispatcherLogger(logger log.Logger) CommandDispatcherOption {
 		dispatcher.logger = logger
 	}
 }
+
+// WithCommandDispatcherMetrics is an option to set the metrics.Registry of the CommandDispatcher
+func WithCommandDispatcherMetrics(registry metrics.Registry) CommandDispatcherOption {
+	return func(dispatcher *CommandDispatcher) {
+		dispatcher.registry = registry
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -97,6 +98,35 @@ func TestCommandDispatcher_ReceiveMessage(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"Expired": {
+			fields: fields{
+				publisher: msgtest.MockReplyMessagePublisher(func(m *msgmocks.ReplyMessagePublisher) {
+					m.On("PublishReply", mock.Anything, mock.AnythingOfType("msg.Expired"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				}),
+				handlers: []handler{
+					{
+						cmd: coretest.Command{},
+						fn: func(ctx context.Context, command msg.Command) ([]msg.Reply, error) {
+							return []msg.Reply{msg.WithSuccess()}, nil
+						},
+					},
+				},
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Sub", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(m)
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Debug", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Warn", "command has expired", mock.Anything)
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+					msg.MessageCommandName:         coretest.Command{}.CommandName(),
+					msg.MessageCommandReplyChannel: "reply-channel",
+				}), msg.WithExpiresAt(time.Now().Add(-time.Minute))),
+			},
+			wantErr: false,
+		},
 		"UnregisteredCommand": {
 			fields: fields{
 				publisher: msgtest.MockReplyMessagePublisher(func(m *msgmocks.ReplyMessagePublisher) {}),
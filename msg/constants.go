@@ -1,5 +1,9 @@
 package msg
 
+import (
+	"time"
+)
+
 // Message header keys
 const (
 	MessageID            = "ID"
@@ -7,6 +11,11 @@ const (
 	MessageChannel       = "CHANNEL"
 	MessageCorrelationID = "CORRELATION_ID"
 	MessageCausationID   = "CAUSATION_ID"
+	MessageContentType   = "CONTENT_TYPE"
+	MessageDeliverAt     = "DELIVER_AT"
+	MessageExpiresAt     = "EXPIRES_AT"
+	MessageTraceParent   = "TRACEPARENT"
+	MessageTraceState    = "TRACESTATE"
 
 	MessageEventPrefix     = "EVENT_"
 	MessageEventName       = MessageEventPrefix + "NAME"
@@ -17,8 +26,16 @@ const (
 	MessageCommandName         = MessageCommandPrefix + "NAME"
 	MessageCommandChannel      = MessageCommandPrefix + "CHANNEL"
 	MessageCommandReplyChannel = MessageCommandPrefix + "REPLY_CHANNEL"
+	MessageCommandClientID     = MessageCommandPrefix + "CLIENT_ID"
 
-	MessageReplyPrefix  = "REPLY_"
-	MessageReplyName    = MessageReplyPrefix + "NAME"
-	MessageReplyOutcome = MessageReplyPrefix + "OUTCOME"
+	MessageReplyPrefix   = "REPLY_"
+	MessageReplyName     = MessageReplyPrefix + "NAME"
+	MessageReplyOutcome  = MessageReplyPrefix + "OUTCOME"
+	MessageReplyClientID = MessageReplyPrefix + "CLIENT_ID"
+)
+
+// Package defaults
+const (
+	DefaultSchedulerMessagesPerPolling = 500
+	DefaultSchedulerPollingInterval    = 500 * time.Millisecond
 )
@@ -9,3 +9,17 @@ type Consumer interface {
 	Listen(ctx context.Context, channel string, consumer ReceiveMessageFunc) error
 	Close(ctx context.Context) error
 }
+
+// ChannelLister is an optional interface for consumers that are able to report the channels that exist
+//
+// Subscribers will resolve channel patterns against the reported channels when the consumer is started
+type ChannelLister interface {
+	Channels(ctx context.Context) ([]string, error)
+}
+
+// PatternConsumer is an optional interface for consumers that are able to listen to channel patterns directly
+//
+// PatternConsumer is used for channel patterns when the consumer does not also implement ChannelLister
+type PatternConsumer interface {
+	ListenPattern(ctx context.Context, pattern string, consumer ReceiveMessageFunc) error
+}
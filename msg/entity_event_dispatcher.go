@@ -2,9 +2,11 @@ package msg
 
 import (
 	"context"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // EntityEventHandlerFunc function handlers for msg.EntityEvent
@@ -12,8 +14,12 @@ type EntityEventHandlerFunc func(context.Context, EntityEvent) error
 
 // EntityEventDispatcher is a MessageReceiver for DomainEvents
 type EntityEventDispatcher struct {
-	handlers map[string]EntityEventHandlerFunc
-	logger   log.Logger
+	handlers  map[string][]EntityEventHandlerFunc
+	catchAlls []EntityEventHandlerFunc
+	fallback  ReceiveMessageFunc
+	logger    log.Logger
+	registry  metrics.Registry
+	metrics   HandlerMetrics
 }
 
 var _ MessageReceiver = (*EntityEventDispatcher)(nil)
@@ -21,23 +27,47 @@ var _ MessageReceiver = (*EntityEventDispatcher)(nil)
 // NewEntityEventDispatcher constructs a new EntityEventDispatcher
 func NewEntityEventDispatcher(options ...EntityEventDispatcherOption) *EntityEventDispatcher {
 	c := &EntityEventDispatcher{
-		handlers: map[string]EntityEventHandlerFunc{},
+		handlers: map[string][]EntityEventHandlerFunc{},
 		logger:   log.DefaultLogger,
+		registry: metrics.DefaultRegistry,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
+	c.metrics = NewHandlerMetrics(c.registry)
+
 	c.logger.Trace("msg.EntityEventDispatcher constructed")
 
 	return c
 }
 
 // Handle adds a new Event that will be handled by EventMessageFunc handler
+//
+// Multiple handlers may be added for an Event and will be run in the order they were added
 func (d *EntityEventDispatcher) Handle(evt core.Event, handler EntityEventHandlerFunc) *EntityEventDispatcher {
 	d.logger.Trace("entity event handler added", log.String("EventName", evt.EventName()))
-	d.handlers[evt.EventName()] = handler
+	d.handlers[evt.EventName()] = append(d.handlers[evt.EventName()], handler)
+	return d
+}
+
+// HandleAll adds a handler that will receive every event
+//
+// Catch-all handlers are run after the handlers for the event name, or after the fallback for events without
+// any, in the order they were added. Events that could not be deserialized are received as a RawEvent
+func (d *EntityEventDispatcher) HandleAll(handler EntityEventHandlerFunc) *EntityEventDispatcher {
+	d.logger.Trace("catch-all entity event handler added")
+	d.catchAlls = append(d.catchAlls, handler)
+	return d
+}
+
+// Fallback sets the handler that will receive the raw Message of events without any handlers for the event name
+//
+// Both the fallback and the catch-all handlers are run for these events, the fallback being run first
+func (d *EntityEventDispatcher) Fallback(handler ReceiveMessageFunc) *EntityEventDispatcher {
+	d.logger.Trace("fallback entity event handler set")
+	d.fallback = handler
 	return d
 }
 
@@ -70,27 +100,61 @@ func (d *EntityEventDispatcher) ReceiveMessage(ctx context.Context, message Mess
 
 	logger.Debug("received entity event message")
 
-	// check first for a handler of the event; It is possible events might be published into channels
-	// that haven't been registered in our application
-	handler, exists := d.handlers[eventName]
-	if !exists {
-		return nil
+	handlers := d.handlers[eventName]
+
+	var errs HandlerErrors
+
+	// It is possible events might be published into channels that haven't been registered in our application
+	if len(handlers) == 0 {
+		if d.fallback != nil {
+			logger.Trace("using fallback entity event handler")
+			err = d.fallback(ctx, message)
+			if err != nil {
+				logger.Error("fallback entity event handler returned an error", log.Error(err))
+				errs = append(errs, err)
+			}
+		}
+
+		if len(d.catchAlls) == 0 {
+			return errs.errorOrNil()
+		}
+	} else {
+		logger.Trace("entity event handler found", log.Int("HandlerCount", len(handlers)))
 	}
 
-	logger.Trace("entity event handler found")
-
-	event, err := core.DeserializeEvent(eventName, message.Payload())
+	event, err := core.DeserializeEvent(eventName, message.Payload(), message.Headers().Get(MessageContentType))
 	if err != nil {
 		logger.Error("error decoding entity event message payload", log.Error(err))
-		return nil
+		if len(d.catchAlls) == 0 {
+			return errs.errorOrNil()
+		}
+
+		// catch-all handlers still receive the events that are unknown to this application
+		handlers = nil
+		event = RawEvent{message}
 	}
 
 	evtMsg := entityEventMessage{entityID, entityName, event, message.Headers()}
 
-	err = handler(ctx, evtMsg)
-	if err != nil {
-		logger.Error("entity event handler returned an error", log.Error(err))
+	started := time.Now()
+	for _, handler := range handlers {
+		err = handler(ctx, evtMsg)
+		if err != nil {
+			logger.Error("entity event handler returned an error", log.Error(err))
+			errs = append(errs, err)
+		}
 	}
 
+	for _, handler := range d.catchAlls {
+		err = handler(ctx, evtMsg)
+		if err != nil {
+			logger.Error("catch-all entity event handler returned an error", log.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	err = errs.errorOrNil()
+	d.metrics.Observe("msg.EntityEventDispatcher", eventName, started, err)
+
 	return err
 }
@@ -2,6 +2,7 @@ package msg
 
 import (
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // EntityEventDispatcherOption options for EntityEventDispatcher
@@ -13,3 +14,10 @@ func WithEntityEventDispatcherLogger(logger log.Logger) EntityEventDispatcherOpt
 		dispatcher.logger = logger
 	}
 }
+
+// WithEntityEventDispatcherMetrics is an option to set the metrics.Registry of the EntityEventDispatcher
+func WithEntityEventDispatcherMetrics(registry metrics.Registry) EntityEventDispatcherOption {
+	return func(dispatcher *EntityEventDispatcher) {
+		dispatcher.registry = registry
+	}
+}
@@ -16,11 +16,28 @@ type Event interface {
 	Headers() Headers
 }
 
+// RawEvent is given to catch-all handlers in place of events that could not be deserialized
+//
+// Events that have not been registered with core are received as a RawEvent with the original Message
+type RawEvent struct {
+	Message
+}
+
+// EventName implements core.Event.EventName
+func (e RawEvent) EventName() string {
+	return e.Headers().Get(MessageEventName)
+}
+
 type eventMessage struct {
 	event   core.Event
 	headers Headers
 }
 
+// NewEvent constructs a new event with headers
+func NewEvent(event core.Event, headers Headers) Event {
+	return eventMessage{event, headers}
+}
+
 func (m eventMessage) Event() core.Event {
 	return m.event
 }
@@ -2,9 +2,11 @@ package msg
 
 import (
 	"context"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // EventHandlerFunc function handlers for msg.Event
@@ -12,8 +14,12 @@ type EventHandlerFunc func(context.Context, Event) error
 
 // EventDispatcher is a MessageReceiver for Events
 type EventDispatcher struct {
-	handlers map[string]EventHandlerFunc
-	logger   log.Logger
+	handlers  map[string][]EventHandlerFunc
+	catchAlls []EventHandlerFunc
+	fallback  ReceiveMessageFunc
+	logger    log.Logger
+	registry  metrics.Registry
+	metrics   HandlerMetrics
 }
 
 var _ MessageReceiver = (*EventDispatcher)(nil)
@@ -21,23 +27,47 @@ var _ MessageReceiver = (*EventDispatcher)(nil)
 // NewEventDispatcher constructs a new EventDispatcher
 func NewEventDispatcher(options ...EventDispatcherOption) *EventDispatcher {
 	c := &EventDispatcher{
-		handlers: map[string]EventHandlerFunc{},
+		handlers: map[string][]EventHandlerFunc{},
 		logger:   log.DefaultLogger,
+		registry: metrics.DefaultRegistry,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
+	c.metrics = NewHandlerMetrics(c.registry)
+
 	c.logger.Trace("msg.EventDispatcher constructed")
 
 	return c
 }
 
 // Handle adds a new Event that will be handled by EventMessageFunc handler
+//
+// Multiple handlers may be added for an Event and will be run in the order they were added
 func (d *EventDispatcher) Handle(evt core.Event, handler EventHandlerFunc) *EventDispatcher {
 	d.logger.Trace("event handler added", log.String("EventName", evt.EventName()))
-	d.handlers[evt.EventName()] = handler
+	d.handlers[evt.EventName()] = append(d.handlers[evt.EventName()], handler)
+	return d
+}
+
+// HandleAll adds a handler that will receive every event
+//
+// Catch-all handlers are run after the handlers for the event name, or after the fallback for events without
+// any, in the order they were added. Events that could not be deserialized are received as a RawEvent
+func (d *EventDispatcher) HandleAll(handler EventHandlerFunc) *EventDispatcher {
+	d.logger.Trace("catch-all event handler added")
+	d.catchAlls = append(d.catchAlls, handler)
+	return d
+}
+
+// Fallback sets the handler that will receive the raw Message of events without any handlers for the event name
+//
+// Both the fallback and the catch-all handlers are run for these events, the fallback being run first
+func (d *EventDispatcher) Fallback(handler ReceiveMessageFunc) *EventDispatcher {
+	d.logger.Trace("fallback event handler set")
+	d.fallback = handler
 	return d
 }
 
@@ -56,27 +86,61 @@ func (d *EventDispatcher) ReceiveMessage(ctx context.Context, message Message) e
 
 	logger.Debug("received event message")
 
-	// check first for a handler of the event; It is possible events might be published into channels
-	// that haven't been registered in our application
-	handler, exists := d.handlers[eventName]
-	if !exists {
-		return nil
+	handlers := d.handlers[eventName]
+
+	var errs HandlerErrors
+
+	// It is possible events might be published into channels that haven't been registered in our application
+	if len(handlers) == 0 {
+		if d.fallback != nil {
+			logger.Trace("using fallback event handler")
+			err = d.fallback(ctx, message)
+			if err != nil {
+				logger.Error("fallback event handler returned an error", log.Error(err))
+				errs = append(errs, err)
+			}
+		}
+
+		if len(d.catchAlls) == 0 {
+			return errs.errorOrNil()
+		}
+	} else {
+		logger.Trace("event handler found", log.Int("HandlerCount", len(handlers)))
 	}
 
-	logger.Trace("event handler found")
-
-	event, err := core.DeserializeEvent(eventName, message.Payload())
+	event, err := core.DeserializeEvent(eventName, message.Payload(), message.Headers().Get(MessageContentType))
 	if err != nil {
 		logger.Error("error decoding event message payload", log.Error(err))
-		return nil
+		if len(d.catchAlls) == 0 {
+			return errs.errorOrNil()
+		}
+
+		// catch-all handlers still receive the events that are unknown to this application
+		handlers = nil
+		event = RawEvent{message}
 	}
 
 	evtMsg := eventMessage{event, message.Headers()}
 
-	err = handler(ctx, evtMsg)
-	if err != nil {
-		logger.Error("event handler returned an error", log.Error(err))
+	started := time.Now()
+	for _, handler := range handlers {
+		err = handler(ctx, evtMsg)
+		if err != nil {
+			logger.Error("event handler returned an error", log.Error(err))
+			errs = append(errs, err)
+		}
 	}
 
+	for _, handler := range d.catchAlls {
+		err = handler(ctx, evtMsg)
+		if err != nil {
+			logger.Error("catch-all event handler returned an error", log.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	err = errs.errorOrNil()
+	d.metrics.Observe("msg.EventDispatcher", eventName, started, err)
+
 	return err
 }
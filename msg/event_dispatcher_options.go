@@ -2,6 +2,7 @@ package msg
 
 import (
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // EventDispatcherOption options for EventDispatcher
@@ -13,3 +14,10 @@ func WithEventDispatcherLogger(logger log.Logger) EventDispatcherOption {
 		dispatcher.logger = logger
 	}
 }
+
+// WithEventDispatcherMetrics is an option to set the metrics.Registry of the EventDispatcher
+func WithEventDispatcherMetrics(registry metrics.Registry) EventDispatcherOption {
+	return func(dispatcher *EventDispatcher) {
+		dispatcher.registry = registry
+	}
+}
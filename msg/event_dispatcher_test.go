@@ -2,7 +2,9 @@ package msg_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -146,3 +148,88 @@ func TestEventDispatcher_ReceiveMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestEventDispatcher_ReceiveMessageHandlers(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	core.RegisterEvents(coretest.Event{})
+
+	errHandler := fmt.Errorf("handler-error")
+
+	newMessage := func(eventName string) msg.Message {
+		return msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+			msg.MessageEventName: eventName,
+		}))
+	}
+
+	tests := map[string]struct {
+		message   msg.Message
+		failing   string
+		wantOrder string
+		wantErrs  int
+	}{
+		"Handlers": {
+			message:   newMessage(coretest.Event{}.EventName()),
+			wantOrder: "ABC",
+			wantErrs:  0,
+		},
+		"HandlerErrors": {
+			message:   newMessage(coretest.Event{}.EventName()),
+			failing:   "AC",
+			wantOrder: "ABC",
+			wantErrs:  2,
+		},
+		"Fallback": {
+			message:   newMessage("unknown"),
+			wantOrder: "FC",
+			wantErrs:  0,
+		},
+		"FallbackError": {
+			message:   newMessage("unknown"),
+			failing:   "FC",
+			wantOrder: "FC",
+			wantErrs:  2,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			order := ""
+			record := func(name string) error {
+				order += name
+				if strings.Contains(tt.failing, name) {
+					return errHandler
+				}
+				return nil
+			}
+			d := msg.NewEventDispatcher()
+			d.HandleAll(func(_ context.Context, evt msg.Event) error {
+				// unknown events are received as the raw message
+				raw, ok := evt.Event().(msg.RawEvent)
+				if unknown := tt.message.Headers().Get(msg.MessageEventName) == "unknown"; ok != unknown || ok && raw.ID() != tt.message.ID() {
+					t.Errorf("HandleAll() event = %T, want raw message %v", evt.Event(), unknown)
+				}
+				return record("C")
+			})
+			d.Handle(coretest.Event{}, func(context.Context, msg.Event) error { return record("A") })
+			d.Handle(coretest.Event{}, func(context.Context, msg.Event) error { return record("B") })
+			d.Fallback(func(context.Context, msg.Message) error { return record("F") })
+
+			err := d.ReceiveMessage(context.Background(), tt.message)
+			if order != tt.wantOrder {
+				t.Errorf("ReceiveMessage() order = %s, want %s", order, tt.wantOrder)
+			}
+			if tt.wantErrs == 0 {
+				if err != nil {
+					t.Errorf("ReceiveMessage() error = %v, want nil", err)
+				}
+				return
+			}
+			var errs msg.HandlerErrors
+			if !errors.As(err, &errs) || len(errs) != tt.wantErrs {
+				t.Errorf("ReceiveMessage() error = %v, wantErrs %d", err, tt.wantErrs)
+			}
+			if !errors.Is(err, errHandler) {
+				t.Errorf("ReceiveMessage() error = %v, want %v", err, errHandler)
+			}
+		})
+	}
+}
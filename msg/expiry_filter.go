@@ -0,0 +1,85 @@
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/stackus/edat/log"
+)
+
+// ExpiryFilter drops or dead-letters expired messages before they reach any receivers
+//
+// Expired commands are passed along so that the command dispatchers may reply with an Expired reply
+//  filter := msg.NewExpiryFilter(msg.WithExpiryFilterDeadLetter(producer, "dead-letters"))
+//  subscriber.Use(filter.SubscriberMiddleware)
+type ExpiryFilter struct {
+	producer          Producer
+	deadLetterChannel string
+	logger            log.Logger
+}
+
+// NewExpiryFilter constructs a new ExpiryFilter
+func NewExpiryFilter(options ...ExpiryFilterOption) *ExpiryFilter {
+	f := &ExpiryFilter{
+		logger: log.DefaultLogger,
+	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	f.logger.Trace("msg.ExpiryFilter constructed")
+
+	return f
+}
+
+// SubscriberMiddleware is a Subscriber middleware that keeps expired messages from reaching the receiver
+func (f *ExpiryFilter) SubscriberMiddleware(next MessageReceiver) MessageReceiver {
+	return ReceiveMessageFunc(func(ctx context.Context, message Message) error {
+		if !IsExpired(message) {
+			return next.ReceiveMessage(ctx, message)
+		}
+
+		// commands are replied to by the dispatchers so that the sender learns of the expiry
+		if message.Headers().Has(MessageCommandName) {
+			return next.ReceiveMessage(ctx, message)
+		}
+
+		logger := f.logger.Sub(
+			log.String("MessageID", message.ID()),
+			log.String("ExpiresAt", message.Headers().Get(MessageExpiresAt)),
+		)
+
+		if f.producer == nil {
+			logger.Debug("dropping expired message")
+			return nil
+		}
+
+		logger.Debug("dead-lettering expired message", log.String("Destination", f.deadLetterChannel))
+
+		err := f.producer.Send(ctx, f.deadLetterChannel, message)
+		if err != nil {
+			logger.Error("error dead-lettering expired message", log.Error(err))
+			return err
+		}
+
+		return nil
+	})
+}
+
+// IsExpired returns whether or not the message has an expiry time that has passed
+//
+// Messages with a malformed expiry time are treated as not having one
+func IsExpired(message Message) bool {
+	value := message.Headers().Get(MessageExpiresAt)
+	if value == "" {
+		return false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false
+	}
+
+	return time.Now().After(expiresAt)
+}
@@ -0,0 +1,23 @@
+package msg
+
+import (
+	"github.com/stackus/edat/log"
+)
+
+// ExpiryFilterOption options for ExpiryFilter
+type ExpiryFilterOption func(*ExpiryFilter)
+
+// WithExpiryFilterDeadLetter is an option to send expired messages into a dead letter channel instead of dropping them
+func WithExpiryFilterDeadLetter(producer Producer, channel string) ExpiryFilterOption {
+	return func(filter *ExpiryFilter) {
+		filter.producer = producer
+		filter.deadLetterChannel = channel
+	}
+}
+
+// WithExpiryFilterLogger is an option to set the log.Logger of the ExpiryFilter
+func WithExpiryFilterLogger(logger log.Logger) ExpiryFilterOption {
+	return func(filter *ExpiryFilter) {
+		filter.logger = logger
+	}
+}
@@ -0,0 +1,113 @@
+package msg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/msg/msgmocks"
+	"github.com/stackus/edat/msg/msgtest"
+)
+
+func TestExpiryFilter_SubscriberMiddleware(t *testing.T) {
+	type args struct {
+		ctx     context.Context
+		message msg.Message
+	}
+
+	expired := msg.NewMessage([]byte(`{}`), msg.WithExpiresAt(time.Now().Add(-time.Minute)))
+
+	tests := map[string]struct {
+		args         args
+		producer     *msgmocks.Producer
+		wantReceived bool
+		wantErr      bool
+	}{
+		"NoExpiry": {
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`)),
+			},
+			producer:     msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			wantReceived: true,
+			wantErr:      false,
+		},
+		"NotExpired": {
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithTTL(time.Minute)),
+			},
+			producer:     msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			wantReceived: true,
+			wantErr:      false,
+		},
+		"Dropped": {
+			args: args{
+				ctx:     context.Background(),
+				message: expired,
+			},
+			producer:     msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			wantReceived: false,
+			wantErr:      false,
+		},
+		"DeadLettered": {
+			args: args{
+				ctx:     context.Background(),
+				message: expired,
+			},
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {
+				m.On("Send", mock.Anything, "dead-letters", expired).Return(nil)
+			}),
+			wantReceived: false,
+			wantErr:      false,
+		},
+		"DeadLetterError": {
+			args: args{
+				ctx:     context.Background(),
+				message: expired,
+			},
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {
+				m.On("Send", mock.Anything, "dead-letters", expired).Return(fmt.Errorf("producer-error"))
+			}),
+			wantReceived: false,
+			wantErr:      true,
+		},
+		"ExpiredCommand": {
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{}`),
+					msg.WithHeaders(map[string]string{msg.MessageCommandName: "command"}),
+					msg.WithExpiresAt(time.Now().Add(-time.Minute)),
+				),
+			},
+			producer:     msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			wantReceived: true,
+			wantErr:      false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var options []msg.ExpiryFilterOption
+			if len(tt.producer.ExpectedCalls) > 0 {
+				options = append(options, msg.WithExpiryFilterDeadLetter(tt.producer, "dead-letters"))
+			}
+			f := msg.NewExpiryFilter(options...)
+			received := false
+			receiver := f.SubscriberMiddleware(msg.ReceiveMessageFunc(func(context.Context, msg.Message) error {
+				received = true
+				return nil
+			}))
+			if err := receiver.ReceiveMessage(tt.args.ctx, tt.args.message); (err != nil) != tt.wantErr {
+				t.Errorf("ReceiveMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if received != tt.wantReceived {
+				t.Errorf("ReceiveMessage() received = %v, want %v", received, tt.wantReceived)
+			}
+			mock.AssertExpectationsForObjects(t, tt.producer)
+		})
+	}
+}
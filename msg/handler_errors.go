@@ -0,0 +1,51 @@
+package msg
+
+import (
+	"errors"
+	"strings"
+)
+
+// HandlerErrors is returned by dispatchers when one or more of the handlers of a message have returned an error
+//
+// The errors are kept in the order the handlers were run in
+type HandlerErrors []error
+
+// Error implements error.Error
+func (e HandlerErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is returns whether or not any of the handler errors match the target
+func (e HandlerErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first handler error that matches the target
+func (e HandlerErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e HandlerErrors) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,34 @@
+package msg
+
+import (
+	"time"
+
+	"github.com/stackus/edat/metrics"
+)
+
+// HandlerMetrics records the outcome and latency of the handlers of dispatchers
+type HandlerMetrics struct {
+	handled  metrics.Counter
+	errors   metrics.Counter
+	duration metrics.Histogram
+}
+
+// NewHandlerMetrics constructs a new HandlerMetrics using the metrics of the registry
+func NewHandlerMetrics(registry metrics.Registry) HandlerMetrics {
+	return HandlerMetrics{
+		handled:  registry.Counter(metrics.MessagesHandled, "Number of messages handled by dispatchers"),
+		errors:   registry.Counter(metrics.MessageHandlerErrors, "Number of errors returned by dispatcher handlers"),
+		duration: registry.Histogram(metrics.MessageHandlerDuration, "Time spent in dispatcher handlers in seconds", metrics.DefaultBuckets),
+	}
+}
+
+// Observe records a handled message for the dispatcher and the name of the message along with the handler latency
+func (m HandlerMetrics) Observe(dispatcher, name string, started time.Time, err error) {
+	labels := metrics.Labels{"dispatcher": dispatcher, "name": name}
+
+	m.handled.Inc(labels)
+	m.duration.Observe(time.Since(started).Seconds(), labels)
+	if err != nil {
+		m.errors.Inc(labels)
+	}
+}
@@ -1,6 +1,8 @@
 package msg
 
 import (
+	"time"
+
 	"github.com/stackus/edat/es"
 )
 
@@ -33,6 +35,47 @@ func WithHeaders(headers Headers) MessageOption {
 	}
 }
 
+// WithContentType is an option to set the content type of the Message payload
+//
+// Blank content types will be ignored
+func WithContentType(contentType string) MessageOption {
+	return func(m *message) {
+		if contentType != "" {
+			m.headers[MessageContentType] = contentType
+		}
+	}
+}
+
+// WithDeliverAt is an option to delay the delivery of the Message until the given time
+//
+// Delayed messages require a Scheduler middleware to be used by the Publisher
+func WithDeliverAt(deliverAt time.Time) MessageOption {
+	return func(m *message) {
+		m.headers[MessageDeliverAt] = deliverAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// WithDelay is an option to delay the delivery of the Message by the given duration
+//
+// Delayed messages require a Scheduler middleware to be used by the Publisher
+func WithDelay(delay time.Duration) MessageOption {
+	return WithDeliverAt(time.Now().Add(delay))
+}
+
+// WithExpiresAt is an option to set the time after which the Message should no longer be processed
+//
+// Expired messages are dropped by an ExpiryFilter middleware; Expired commands receive an Expired reply
+func WithExpiresAt(expiresAt time.Time) MessageOption {
+	return func(m *message) {
+		m.headers[MessageExpiresAt] = expiresAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// WithTTL is an option to set how long the Message may wait before it should no longer be processed
+func WithTTL(ttl time.Duration) MessageOption {
+	return WithExpiresAt(time.Now().Add(ttl))
+}
+
 // WithAggregateInfo is an option to set additional Aggregate specific headers
 func WithAggregateInfo(a *es.AggregateRoot) MessageOption {
 	return func(m *message) {
@@ -0,0 +1,11 @@
+package msgtest
+
+import (
+	"github.com/stackus/edat/msg/msgmocks"
+)
+
+func MockBatchProducer(setup func(m *msgmocks.BatchProducer)) *msgmocks.BatchProducer {
+	m := &msgmocks.BatchProducer{}
+	setup(m)
+	return m
+}
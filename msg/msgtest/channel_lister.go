@@ -0,0 +1,11 @@
+package msgtest
+
+import (
+	"github.com/stackus/edat/msg/msgmocks"
+)
+
+func MockChannelLister(setup func(m *msgmocks.ChannelLister)) *msgmocks.ChannelLister {
+	m := &msgmocks.ChannelLister{}
+	setup(m)
+	return m
+}
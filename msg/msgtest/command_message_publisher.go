@@ -0,0 +1,11 @@
+package msgtest
+
+import (
+	"github.com/stackus/edat/msg/msgmocks"
+)
+
+func MockCommandMessagePublisher(setup func(m *msgmocks.CommandMessagePublisher)) *msgmocks.CommandMessagePublisher {
+	m := &msgmocks.CommandMessagePublisher{}
+	setup(m)
+	return m
+}
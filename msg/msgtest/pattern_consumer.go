@@ -0,0 +1,11 @@
+package msgtest
+
+import (
+	"github.com/stackus/edat/msg/msgmocks"
+)
+
+func MockPatternConsumer(setup func(m *msgmocks.PatternConsumer)) *msgmocks.PatternConsumer {
+	m := &msgmocks.PatternConsumer{}
+	setup(m)
+	return m
+}
@@ -0,0 +1,11 @@
+package msgtest
+
+import (
+	"github.com/stackus/edat/msg/msgmocks"
+)
+
+func MockScheduledMessageStore(setup func(m *msgmocks.ScheduledMessageStore)) *msgmocks.ScheduledMessageStore {
+	m := &msgmocks.ScheduledMessageStore{}
+	setup(m)
+	return m
+}
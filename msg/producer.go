@@ -9,3 +9,10 @@ type Producer interface {
 	Send(ctx context.Context, channel string, message Message) error
 	Close(ctx context.Context) error
 }
+
+// BatchProducer is an optional interface that infrastructures may implement to send many messages at once
+//
+// Publishers will fall back to sending each message with Producer.Send when it has not been implemented
+type BatchProducer interface {
+	SendBatch(ctx context.Context, channel string, messages []Message) error
+}
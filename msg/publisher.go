@@ -7,6 +7,7 @@ import (
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // CommandMessagePublisher interface
@@ -29,22 +30,42 @@ type ReplyMessagePublisher interface {
 	PublishReply(ctx context.Context, reply core.Reply, options ...MessageOption) error
 }
 
+// BatchMessagePublisher interface
+type BatchMessagePublisher interface {
+	PublishBatch(ctx context.Context, messages []Message) error
+}
+
 // MessagePublisher interface
 type MessagePublisher interface {
 	Publish(ctx context.Context, message Message) error
 }
 
+// PublishMessageFunc makes it easy to drop in functions as publishers
+type PublishMessageFunc func(context.Context, Message) error
+
+// Publish implements MessagePublisher.Publish
+func (f PublishMessageFunc) Publish(ctx context.Context, message Message) error {
+	return f(ctx, message)
+}
+
 var _ CommandMessagePublisher = (*Publisher)(nil)
 var _ EntityEventMessagePublisher = (*Publisher)(nil)
 var _ EventMessagePublisher = (*Publisher)(nil)
 var _ ReplyMessagePublisher = (*Publisher)(nil)
 var _ MessagePublisher = (*Publisher)(nil)
+var _ BatchMessagePublisher = (*Publisher)(nil)
 
 // Publisher send domain events, commands, and replies to the publisher
 type Publisher struct {
-	producer Producer
-	logger   log.Logger
-	close    sync.Once
+	producer    Producer
+	logger      log.Logger
+	tracer      core.Tracer
+	registry    metrics.Registry
+	middlewares []func(MessagePublisher) MessagePublisher
+	published   metrics.Counter
+	errors      metrics.Counter
+	duration    metrics.Histogram
+	close       sync.Once
 }
 
 // NewPublisher constructs a new Publisher
@@ -52,17 +73,39 @@ func NewPublisher(producer Producer, options ...PublisherOption) *Publisher {
 	p := &Publisher{
 		producer: producer,
 		logger:   log.DefaultLogger,
+		tracer:   core.DefaultTracer,
+		registry: metrics.DefaultRegistry,
+	}
+
+	p.middlewares = []func(MessagePublisher) MessagePublisher{
+		DateHeaderMiddleware,
+		RequestContextMiddleware,
+		p.traceContextMiddleware,
 	}
 
 	for _, option := range options {
 		option(p)
 	}
 
+	p.published = p.registry.Counter(metrics.MessagesPublished, "Number of messages published")
+	p.errors = p.registry.Counter(metrics.MessagePublishErrors, "Number of errors publishing messages")
+	p.duration = p.registry.Histogram(metrics.MessagePublishDuration, "Time spent publishing messages in seconds", metrics.DefaultBuckets)
+
 	p.logger.Trace("msg.Publisher constructed")
 
 	return p
 }
 
+// Use appends middleware publishers to the publisher stack
+//
+// Middlewares are applied in the order they are added; the first middleware will be the first to
+// receive outgoing messages. The date, correlation, causation, and trace context headers are set by built-in
+// middlewares that will always run before any added middlewares. The trace context headers are replaced by those of
+// the producer span of the message when it is sent.
+func (p *Publisher) Use(mws ...func(MessagePublisher) MessagePublisher) {
+	p.middlewares = append(p.middlewares, mws...)
+}
+
 // PublishCommand serializes a command into a message with command specific headers and publishes it to a producer
 func (p *Publisher) PublishCommand(ctx context.Context, replyChannel string, command core.Command, options ...MessageOption) error {
 	msgOptions := []MessageOption{
@@ -90,6 +133,8 @@ func (p *Publisher) PublishCommand(ctx context.Context, replyChannel string, com
 		return err
 	}
 
+	msgOptions = append(msgOptions, WithContentType(core.GetContentType(command.CommandName())))
+
 	message := NewMessage(payload, msgOptions...)
 
 	err = p.Publish(ctx, message)
@@ -126,6 +171,8 @@ func (p *Publisher) PublishReply(ctx context.Context, reply core.Reply, options
 		return err
 	}
 
+	msgOptions = append(msgOptions, WithContentType(core.GetContentType(reply.ReplyName())))
+
 	message := NewMessage(payload, msgOptions...)
 
 	err = p.Publish(ctx, message)
@@ -136,7 +183,9 @@ func (p *Publisher) PublishReply(ctx context.Context, reply core.Reply, options
 	return err
 }
 
-// PublishEntityEvents serializes entity events into messages with entity specific headers and publishes it to a producer
+// PublishEntityEvents serializes entity events into messages with entity specific headers and publishes them to a producer
+//
+// The events will be sent as a single batch when the producer implements BatchProducer
 func (p *Publisher) PublishEntityEvents(ctx context.Context, entity core.Entity, options ...MessageOption) error {
 	msgOptions := []MessageOption{
 		WithHeaders(map[string]string{
@@ -152,17 +201,26 @@ func (p *Publisher) PublishEntityEvents(ctx context.Context, entity core.Entity,
 
 	msgOptions = append(msgOptions, options...)
 
-	for _, event := range entity.Events() {
-		logger := p.logger.Sub(
-			log.String("EntityID", entity.ID()),
-			log.String("EntityName", entity.EntityName()),
-		)
+	logger := p.logger.Sub(
+		log.String("EntityID", entity.ID()),
+		log.String("EntityName", entity.EntityName()),
+	)
 
-		err := p.PublishEvent(ctx, event, msgOptions...)
+	messages := make([]Message, 0, len(entity.Events()))
+	for _, event := range entity.Events() {
+		message, err := p.eventMessage(p.logger.Sub(log.String("EventName", event.EventName())), event, msgOptions...)
 		if err != nil {
 			logger.Error("error publishing entity event", log.Error(err))
 			return err
 		}
+
+		messages = append(messages, message)
+	}
+
+	err := p.PublishBatch(ctx, messages)
+	if err != nil {
+		logger.Error("error publishing entity event", log.Error(err))
+		return err
 	}
 
 	return nil
@@ -170,32 +228,15 @@ func (p *Publisher) PublishEntityEvents(ctx context.Context, entity core.Entity,
 
 // PublishEvent serializes an event into a message with event specific headers and publishes it to a producer
 func (p *Publisher) PublishEvent(ctx context.Context, event core.Event, options ...MessageOption) error {
-	msgOptions := []MessageOption{
-		WithHeaders(map[string]string{
-			MessageEventName: event.EventName(),
-		}),
-	}
-
-	if v, ok := event.(interface{ DestinationChannel() string }); ok {
-		msgOptions = append(msgOptions, WithDestinationChannel(v.DestinationChannel()))
-	}
-
-	msgOptions = append(msgOptions, options...)
-
 	logger := p.logger.Sub(
 		log.String("EventName", event.EventName()),
 	)
 
-	logger.Trace("publishing event")
-
-	payload, err := core.SerializeEvent(event)
+	message, err := p.eventMessage(logger, event, options...)
 	if err != nil {
-		logger.Error("error serializing event payload", log.Error(err))
 		return err
 	}
 
-	message := NewMessage(payload, msgOptions...)
-
 	err = p.Publish(ctx, message)
 	if err != nil {
 		logger.Error("error publishing event", log.Error(err))
@@ -206,37 +247,122 @@ func (p *Publisher) PublishEvent(ctx context.Context, event core.Event, options
 
 // Publish sends a message off to a producer
 func (p *Publisher) Publish(ctx context.Context, message Message) error {
-	var err error
-	var channel string
-
-	channel, err = message.Headers().GetRequired(MessageChannel)
+	_, err := message.Headers().GetRequired(MessageChannel)
 	if err != nil {
 		return err
 	}
 
-	message.Headers()[MessageDate] = time.Now().Format(time.RFC3339)
+	return p.chain(PublishMessageFunc(p.send)).Publish(ctx, message)
+}
+
+// PublishBatch sends many messages off to a producer
+//
+// The messages are sent in the order given. When the producer implements BatchProducer each run of consecutive
+// messages for the same destination channel is sent as a batch, otherwise each message is sent on its own
+func (p *Publisher) PublishBatch(ctx context.Context, messages []Message) error {
+	for _, message := range messages {
+		_, err := message.Headers().GetRequired(MessageChannel)
+		if err != nil {
+			return err
+		}
+	}
+
+	batchProducer, isBatchProducer := p.producer.(BatchProducer)
+	if !isBatchProducer {
+		for _, message := range messages {
+			err := p.Publish(ctx, message)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var collected []Message
+	collector := p.chain(PublishMessageFunc(func(_ context.Context, message Message) error {
+		collected = append(collected, message)
+		return nil
+	}))
 
-	// Published messages are request boundaries
-	if id, exists := message.Headers()[MessageCorrelationID]; !exists || id == "" {
-		message.Headers()[MessageCorrelationID] = core.GetCorrelationID(ctx)
+	for _, message := range messages {
+		err := collector.Publish(ctx, message)
+		if err != nil {
+			return err
+		}
 	}
 
-	if id, exists := message.Headers()[MessageCausationID]; !exists || id == "" {
-		message.Headers()[MessageCausationID] = core.GetRequestID(ctx)
+	for sent := 0; sent < len(collected); {
+		channel := collected[sent].Headers().Get(MessageChannel)
+
+		next := sent + 1
+		for next < len(collected) && collected[next].Headers().Get(MessageChannel) == channel {
+			next++
+		}
+
+		logger := p.logger.Sub(
+			log.String("Destination", channel),
+			log.Int("MessageCount", next-sent),
+		)
+
+		logger.Trace("publishing message batch")
+
+		spans := make([]core.Span, 0, next-sent)
+		for _, message := range collected[sent:next] {
+			_, span := p.startSpan(ctx, message)
+			spans = append(spans, span)
+		}
+
+		started := time.Now()
+		err := batchProducer.SendBatch(ctx, channel, collected[sent:next])
+		p.observe(channel, next-sent, started, err)
+		for _, span := range spans {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		}
+		sent = next
+		if err != nil {
+			logger.Error("error publishing message batch", log.Error(err))
+			return err
+		}
 	}
 
+	return nil
+}
+
+// Stop stops the publisher and underlying producer
+func (p *Publisher) Stop(ctx context.Context) (err error) {
+	defer p.logger.Trace("publisher stopped")
+	p.close.Do(func() {
+		err = p.producer.Close(ctx)
+	})
+
+	return
+}
+
+func (p *Publisher) send(ctx context.Context, message Message) error {
+	channel := message.Headers().Get(MessageChannel)
+
 	logger := p.logger.Sub(
 		log.String("MessageID", message.ID()),
-		log.String("CorrelationID", message.Headers()[MessageCorrelationID]),
-		log.String("CausationID", message.Headers()[MessageCausationID]),
+		log.String("CorrelationID", message.Headers().Get(MessageCorrelationID)),
+		log.String("CausationID", message.Headers().Get(MessageCausationID)),
 		log.String("Destination", channel),
 		log.Int("PayloadSize", len(message.Payload())),
 	)
 
 	logger.Trace("publishing message")
 
-	err = p.producer.Send(ctx, channel, message)
+	ctx, span := p.startSpan(ctx, message)
+	defer span.End()
+
+	started := time.Now()
+	err := p.producer.Send(ctx, channel, message)
+	p.observe(channel, 1, started, err)
 	if err != nil {
+		span.RecordError(err)
 		logger.Error("error publishing message", log.Error(err))
 		return err
 	}
@@ -244,12 +370,97 @@ func (p *Publisher) Publish(ctx context.Context, message Message) error {
 	return nil
 }
 
-// Stop stops the publisher and underlying producer
-func (p *Publisher) Stop(ctx context.Context) (err error) {
-	defer p.logger.Trace("publisher stopped")
-	p.close.Do(func() {
-		err = p.producer.Close(ctx)
+// startSpan starts a producer span for the message as a child of the trace context in its headers, and sets the
+// trace context headers to those of the new span
+func (p *Publisher) startSpan(ctx context.Context, message Message) (context.Context, core.Span) {
+	channel := message.Headers().Get(MessageChannel)
+
+	ctx = core.ContextWithTraceParent(
+		ctx,
+		message.Headers().Get(MessageTraceParent),
+		message.Headers().Get(MessageTraceState),
+	)
+
+	ctx, span := p.tracer.Start(ctx, "publish "+channel, core.SpanKindProducer)
+
+	span.SetAttribute("MessageID", message.ID())
+	span.SetAttribute("Channel", channel)
+
+	setTraceHeaders(message, span.SpanContext())
+
+	return ctx, span
+}
+
+func (p *Publisher) observe(channel string, count int, started time.Time, err error) {
+	labels := metrics.Labels{"channel": channel}
+
+	p.duration.Observe(time.Since(started).Seconds(), labels)
+	if err != nil {
+		p.errors.Inc(labels)
+		return
+	}
+	p.published.Add(float64(count), labels)
+}
+
+// traceContextMiddleware sets the trace context headers of each outgoing message to the trace context of the request
+//
+// The headers of messages being republished, e.g. from an outbox, are kept when there is no trace context to continue
+// their trace. The producer span of the message is started from these headers once the message is sent
+func (p *Publisher) traceContextMiddleware(next MessagePublisher) MessagePublisher {
+	return PublishMessageFunc(func(ctx context.Context, message Message) error {
+		setTraceHeaders(message, core.GetSpanContext(ctx))
+
+		return next.Publish(ctx, message)
 	})
+}
 
-	return
+// setTraceHeaders sets the trace context headers of the message when the span context is valid
+func setTraceHeaders(message Message, sc core.SpanContext) {
+	if !sc.IsValid() {
+		return
+	}
+
+	message.Headers().Set(MessageTraceParent, sc.TraceParent())
+	if sc.TraceState != "" {
+		message.Headers().Set(MessageTraceState, sc.TraceState)
+	}
+}
+
+func (p *Publisher) chain(publisher MessagePublisher) MessagePublisher {
+	if len(p.middlewares) == 0 {
+		return publisher
+	}
+
+	mp := p.middlewares[len(p.middlewares)-1](publisher)
+	for i := len(p.middlewares) - 2; i >= 0; i-- {
+		mp = p.middlewares[i](mp)
+	}
+
+	return mp
+}
+
+func (p *Publisher) eventMessage(logger log.Logger, event core.Event, options ...MessageOption) (Message, error) {
+	msgOptions := []MessageOption{
+		WithHeaders(map[string]string{
+			MessageEventName: event.EventName(),
+		}),
+	}
+
+	if v, ok := event.(interface{ DestinationChannel() string }); ok {
+		msgOptions = append(msgOptions, WithDestinationChannel(v.DestinationChannel()))
+	}
+
+	msgOptions = append(msgOptions, options...)
+
+	logger.Trace("publishing event")
+
+	payload, err := core.SerializeEvent(event)
+	if err != nil {
+		logger.Error("error serializing event payload", log.Error(err))
+		return nil, err
+	}
+
+	msgOptions = append(msgOptions, WithContentType(core.GetContentType(event.EventName())))
+
+	return NewMessage(payload, msgOptions...), nil
 }
@@ -0,0 +1,34 @@
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/stackus/edat/core"
+)
+
+// DateHeaderMiddleware is a publisher middleware that sets the date header of outgoing messages
+func DateHeaderMiddleware(next MessagePublisher) MessagePublisher {
+	return PublishMessageFunc(func(ctx context.Context, message Message) error {
+		message.Headers().Set(MessageDate, time.Now().Format(time.RFC3339))
+
+		return next.Publish(ctx, message)
+	})
+}
+
+// RequestContextMiddleware is a publisher middleware that sets the correlation and causation headers of
+// outgoing messages using the request context when the headers have not already been set
+func RequestContextMiddleware(next MessagePublisher) MessagePublisher {
+	return PublishMessageFunc(func(ctx context.Context, message Message) error {
+		// Published messages are request boundaries
+		if id := message.Headers().Get(MessageCorrelationID); id == "" {
+			message.Headers().Set(MessageCorrelationID, core.GetCorrelationID(ctx))
+		}
+
+		if id := message.Headers().Get(MessageCausationID); id == "" {
+			message.Headers().Set(MessageCausationID, core.GetRequestID(ctx))
+		}
+
+		return next.Publish(ctx, message)
+	})
+}
@@ -1,7 +1,9 @@
 package msg
 
 import (
+	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // PublisherOption options for PublisherPublisher
@@ -13,3 +15,17 @@ func WithPublisherLogger(logger log.Logger) PublisherOption {
 		publisher.logger = logger
 	}
 }
+
+// WithPublisherTracer is an option to set the core.Tracer of the Publisher
+func WithPublisherTracer(tracer core.Tracer) PublisherOption {
+	return func(publisher *Publisher) {
+		publisher.tracer = tracer
+	}
+}
+
+// WithPublisherMetrics is an option to set the metrics.Registry of the Publisher
+func WithPublisherMetrics(registry metrics.Registry) PublisherOption {
+	return func(publisher *Publisher) {
+		publisher.registry = registry
+	}
+}
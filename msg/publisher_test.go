@@ -3,6 +3,8 @@ package msg_test
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -497,6 +499,319 @@ func TestPublisher_PublishReply(t *testing.T) {
 	}
 }
 
+type batchProducer struct {
+	*msgmocks.Producer
+	*msgmocks.BatchProducer
+}
+
+func TestPublisher_PublishBatch(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		messages []msg.Message
+	}
+
+	newMessage := func(channel string) msg.Message {
+		return msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{
+			msg.MessageChannel: channel,
+		}))
+	}
+
+	tests := map[string]struct {
+		producer      *msgmocks.Producer
+		batchProducer *msgmocks.BatchProducer
+		args          args
+		wantErr       bool
+	}{
+		"BatchProducer": {
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			batchProducer: msgtest.MockBatchProducer(func(m *msgmocks.BatchProducer) {
+				m.On("SendBatch", mock.Anything, "channel-a", mock.MatchedBy(func(messages []msg.Message) bool {
+					return len(messages) == 2
+				})).Return(nil).Once()
+				m.On("SendBatch", mock.Anything, "channel-b", mock.MatchedBy(func(messages []msg.Message) bool {
+					return len(messages) == 1
+				})).Return(nil).Once()
+			}),
+			args: args{
+				ctx:      context.Background(),
+				messages: []msg.Message{newMessage("channel-a"), newMessage("channel-a"), newMessage("channel-b")},
+			},
+			wantErr: false,
+		},
+		"BatchProducerError": {
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			batchProducer: msgtest.MockBatchProducer(func(m *msgmocks.BatchProducer) {
+				m.On("SendBatch", mock.Anything, "channel-a", mock.Anything).Return(fmt.Errorf("producer-error")).Once()
+			}),
+			args: args{
+				ctx:      context.Background(),
+				messages: []msg.Message{newMessage("channel-a"), newMessage("channel-b")},
+			},
+			wantErr: true,
+		},
+		"Producer": {
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {
+				m.On("Send", mock.Anything, "channel-a", mock.Anything).Return(nil).Twice()
+				m.On("Send", mock.Anything, "channel-b", mock.Anything).Return(nil).Once()
+			}),
+			args: args{
+				ctx:      context.Background(),
+				messages: []msg.Message{newMessage("channel-a"), newMessage("channel-b"), newMessage("channel-a")},
+			},
+			wantErr: false,
+		},
+		"MissingChannel": {
+			producer: msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			args: args{
+				ctx:      context.Background(),
+				messages: []msg.Message{newMessage("channel-a"), msg.NewMessage([]byte(`{}`))},
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var producer msg.Producer = tt.producer
+			if tt.batchProducer != nil {
+				producer = batchProducer{tt.producer, tt.batchProducer}
+			}
+			p := msg.NewPublisher(producer)
+			if err := p.PublishBatch(tt.args.ctx, tt.args.messages); (err != nil) != tt.wantErr {
+				t.Errorf("PublishBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mock.AssertExpectationsForObjects(t, tt.producer)
+			if tt.batchProducer != nil {
+				mock.AssertExpectationsForObjects(t, tt.batchProducer)
+			}
+		})
+	}
+}
+
+type orderedProducer struct {
+	tracer *coretest.RecordingTracer
+	sends  []string
+	err    error
+}
+
+func (p *orderedProducer) Send(_ context.Context, channel string, message msg.Message) error {
+	p.sends = append(p.sends, channel+":"+string(message.Payload()))
+	return p.err
+}
+
+func (p *orderedProducer) Close(context.Context) error { return nil }
+
+type orderedBatchProducer struct {
+	*orderedProducer
+}
+
+func (p orderedBatchProducer) SendBatch(_ context.Context, channel string, messages []msg.Message) error {
+	var payloads []string
+	for _, message := range messages {
+		payloads = append(payloads, string(message.Payload()))
+	}
+
+	// the span of each message must still be open while it is being sent
+	var open int
+	for _, span := range p.tracer.Spans() {
+		if !span.Ended {
+			open++
+		}
+	}
+
+	p.sends = append(p.sends, fmt.Sprintf("%s:%s open:%d", channel, strings.Join(payloads, ","), open))
+	return p.err
+}
+
+func TestPublisher_PublishBatch_Order(t *testing.T) {
+	newMessage := func(channel, payload string) msg.Message {
+		return msg.NewMessage([]byte(payload), msg.WithHeaders(map[string]string{
+			msg.MessageChannel: channel,
+		}))
+	}
+
+	tests := map[string]struct {
+		batch      bool
+		err        error
+		wantSends  []string
+		wantErrors int
+	}{
+		"Producer": {
+			batch:     false,
+			wantSends: []string{"a:A1", "b:B1", "a:A2", "a:A3"},
+		},
+		"BatchProducer": {
+			batch:     true,
+			wantSends: []string{"a:A1 open:1", "b:B1 open:1", "a:A2,A3 open:2"},
+		},
+		"BatchProducerError": {
+			batch:      true,
+			err:        fmt.Errorf("producer-error"),
+			wantSends:  []string{"a:A1 open:1"},
+			wantErrors: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracer := coretest.NewRecordingTracer()
+			ordered := &orderedProducer{tracer: tracer, err: tt.err}
+
+			var producer msg.Producer = ordered
+			if tt.batch {
+				producer = orderedBatchProducer{ordered}
+			}
+
+			p := msg.NewPublisher(producer, msg.WithPublisherTracer(tracer))
+			err := p.PublishBatch(context.Background(), []msg.Message{
+				newMessage("a", "A1"), newMessage("b", "B1"), newMessage("a", "A2"), newMessage("a", "A3"),
+			})
+			if (err != nil) != (tt.err != nil) {
+				t.Errorf("PublishBatch() error = %v, wantErr %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(ordered.sends, tt.wantSends) {
+				t.Errorf("PublishBatch() sends = %v, want %v", ordered.sends, tt.wantSends)
+			}
+
+			var errs int
+			for _, span := range tracer.Spans() {
+				if !span.Ended {
+					t.Errorf("span %s was not ended", span.Name)
+				}
+				errs += len(span.Errors)
+			}
+			if errs != tt.wantErrors {
+				t.Errorf("span errors = %d, want %d", errs, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestPublisher_PublishBatch_TraceContext(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tracer := coretest.NewRecordingTracer()
+	p := msg.NewPublisher(orderedBatchProducer{&orderedProducer{tracer: tracer}}, msg.WithPublisherTracer(tracer))
+
+	messages := []msg.Message{
+		msg.NewMessage([]byte(`A1`), msg.WithHeaders(map[string]string{msg.MessageChannel: "a"})),
+		msg.NewMessage([]byte(`B1`), msg.WithHeaders(map[string]string{msg.MessageChannel: "b"})),
+	}
+
+	ctx := core.ContextWithTraceParent(context.Background(), traceParent, "")
+	if err := p.PublishBatch(ctx, messages); err != nil {
+		t.Fatalf("PublishBatch() error = %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != len(messages) {
+		t.Fatalf("PublishBatch() spans = %d, want %d", len(spans), len(messages))
+	}
+	for i, span := range spans {
+		if span.Parent.TraceParent() != traceParent {
+			t.Errorf("span %s parent = %v, want %v", span.Name, span.Parent.TraceParent(), traceParent)
+		}
+		if got := messages[i].Headers().Get(msg.MessageTraceParent); got != span.Context.TraceParent() {
+			t.Errorf("message %d %s = %v, want %v", i, msg.MessageTraceParent, got, span.Context.TraceParent())
+		}
+	}
+}
+
+func TestPublisher_Use(t *testing.T) {
+	type args struct {
+		ctx     context.Context
+		message msg.Message
+	}
+
+	// record appends the name to the order header to track the order the middlewares were run in
+	record := func(name string) func(msg.MessagePublisher) msg.MessagePublisher {
+		return func(next msg.MessagePublisher) msg.MessagePublisher {
+			return msg.PublishMessageFunc(func(ctx context.Context, message msg.Message) error {
+				message.Headers().Set("order", message.Headers().Get("order")+name)
+				return next.Publish(ctx, message)
+			})
+		}
+	}
+
+	tests := map[string]struct {
+		middlewares []func(msg.MessagePublisher) msg.MessagePublisher
+		args        args
+		wantHeaders map[string]string
+		wantErr     bool
+	}{
+		"Ordering": {
+			middlewares: []func(msg.MessagePublisher) msg.MessagePublisher{record("a"), record("b"), record("c")},
+			args: args{
+				ctx: core.SetRequestContext(context.Background(), "request-id", "correlation-id", "causation-id"),
+				message: msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{
+					msg.MessageChannel: "message-channel",
+				})),
+			},
+			wantHeaders: map[string]string{
+				"order":                  "abc",
+				msg.MessageCorrelationID: "correlation-id",
+				msg.MessageCausationID:   "request-id",
+			},
+			wantErr: false,
+		},
+		"ExistingHeaders": {
+			middlewares: []func(msg.MessagePublisher) msg.MessagePublisher{},
+			args: args{
+				ctx: core.SetRequestContext(context.Background(), "request-id", "correlation-id", "causation-id"),
+				message: msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{
+					msg.MessageChannel:       "message-channel",
+					msg.MessageCorrelationID: "original-correlation-id",
+					msg.MessageCausationID:   "original-causation-id",
+				})),
+			},
+			wantHeaders: map[string]string{
+				msg.MessageCorrelationID: "original-correlation-id",
+				msg.MessageCausationID:   "original-causation-id",
+			},
+			wantErr: false,
+		},
+		"MiddlewareError": {
+			middlewares: []func(msg.MessagePublisher) msg.MessagePublisher{
+				func(next msg.MessagePublisher) msg.MessagePublisher {
+					return msg.PublishMessageFunc(func(ctx context.Context, message msg.Message) error {
+						return fmt.Errorf("middleware-error")
+					})
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{
+					msg.MessageChannel: "message-channel",
+				})),
+			},
+			wantHeaders: map[string]string{},
+			wantErr:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			producer := msgtest.MockProducer(func(m *msgmocks.Producer) {
+				if !tt.wantErr {
+					m.On("Send", mock.Anything, "message-channel", mock.Anything).Return(nil)
+				}
+			})
+			p := msg.NewPublisher(producer)
+			p.Use(tt.middlewares...)
+			if err := p.Publish(tt.args.ctx, tt.args.message); (err != nil) != tt.wantErr {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for key, value := range tt.wantHeaders {
+				if got := tt.args.message.Headers().Get(key); got != value {
+					t.Errorf("Publish() header %s = %v, want %v", key, got, value)
+				}
+			}
+			if !tt.wantErr && !tt.args.message.Headers().Has(msg.MessageDate) {
+				t.Errorf("Publish() missing %s header", msg.MessageDate)
+			}
+			mock.AssertExpectationsForObjects(t, producer)
+		})
+	}
+}
+
 func TestPublisher_Stop(t *testing.T) {
 	type fields struct {
 		producer msg.Producer
@@ -0,0 +1,50 @@
+package msg
+
+import (
+	"strings"
+
+	"github.com/stackus/edat/core"
+)
+
+// ReceivedReply is a core.Reply received in response to a command with message header information
+type ReceivedReply interface {
+	Reply() core.Reply
+	Headers() Headers
+	Outcome() string
+	CorrelationHeaders() Headers
+}
+
+type receivedReplyMessage struct {
+	reply   core.Reply
+	outcome string
+	headers Headers
+}
+
+func (m receivedReplyMessage) Reply() core.Reply {
+	return m.reply
+}
+
+func (m receivedReplyMessage) Headers() Headers {
+	return m.headers
+}
+
+// Outcome returns either ReplyOutcomeSuccess or ReplyOutcomeFailure
+func (m receivedReplyMessage) Outcome() string {
+	return m.outcome
+}
+
+// CorrelationHeaders returns the command headers that were returned with the reply
+func (m receivedReplyMessage) CorrelationHeaders() Headers {
+	headers := make(map[string]string)
+	for key, value := range m.headers {
+		if key == MessageReplyName || key == MessageReplyOutcome {
+			continue
+		}
+
+		if strings.HasPrefix(key, MessageReplyPrefix) {
+			headers[key] = value
+		}
+	}
+
+	return headers
+}
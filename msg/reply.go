@@ -101,3 +101,14 @@ func WithFailure() Reply {
 		},
 	}
 }
+
+// WithExpired returns an Expired Failure reply
+func WithExpired() Reply {
+	return &replyMessage{
+		reply: Expired{},
+		headers: map[string]string{
+			MessageReplyOutcome: ReplyOutcomeFailure,
+			MessageReplyName:    Expired{}.ReplyName(),
+		},
+	}
+}
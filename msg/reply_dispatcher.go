@@ -0,0 +1,97 @@
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
+)
+
+// ReplyHandlerFunc function handlers for msg.ReceivedReply
+type ReplyHandlerFunc func(context.Context, ReceivedReply) error
+
+// ReplyDispatcher is a MessageReceiver for Replies
+type ReplyDispatcher struct {
+	handlers map[string]ReplyHandlerFunc
+	logger   log.Logger
+	registry metrics.Registry
+	metrics  HandlerMetrics
+}
+
+var _ MessageReceiver = (*ReplyDispatcher)(nil)
+
+// NewReplyDispatcher constructs a new ReplyDispatcher
+func NewReplyDispatcher(options ...ReplyDispatcherOption) *ReplyDispatcher {
+	c := &ReplyDispatcher{
+		handlers: map[string]ReplyHandlerFunc{},
+		logger:   log.DefaultLogger,
+		registry: metrics.DefaultRegistry,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.metrics = NewHandlerMetrics(c.registry)
+
+	c.logger.Trace("msg.ReplyDispatcher constructed")
+
+	return c
+}
+
+// Handle adds a new Reply that will be handled by handler
+func (d *ReplyDispatcher) Handle(reply core.Reply, handler ReplyHandlerFunc) *ReplyDispatcher {
+	d.logger.Trace("reply handler added", log.String("ReplyName", reply.ReplyName()))
+	d.handlers[reply.ReplyName()] = handler
+	return d
+}
+
+// ReceiveMessage implements MessageReceiver.ReceiveMessage
+func (d *ReplyDispatcher) ReceiveMessage(ctx context.Context, message Message) error {
+	replyName, err := message.Headers().GetRequired(MessageReplyName)
+	if err != nil {
+		d.logger.Error("error reading reply name", log.Error(err))
+		return nil
+	}
+
+	logger := d.logger.Sub(
+		log.String("ReplyName", replyName),
+		log.String("MessageID", message.ID()),
+	)
+
+	logger.Debug("received reply message")
+
+	// check first for a handler of the reply; It is possible replies might be published into channels
+	// that haven't been registered in our application
+	handler, exists := d.handlers[replyName]
+	if !exists {
+		return nil
+	}
+
+	logger.Trace("reply handler found")
+
+	outcome, err := message.Headers().GetRequired(MessageReplyOutcome)
+	if err != nil {
+		logger.Error("error reading reply outcome", log.Error(err))
+		return nil
+	}
+
+	reply, err := core.DeserializeReply(replyName, message.Payload(), message.Headers().Get(MessageContentType))
+	if err != nil {
+		logger.Error("error decoding reply message payload", log.Error(err))
+		return nil
+	}
+
+	replyMsg := receivedReplyMessage{reply, outcome, message.Headers()}
+
+	started := time.Now()
+	err = handler(ctx, replyMsg)
+	d.metrics.Observe("msg.ReplyDispatcher", replyName, started, err)
+	if err != nil {
+		logger.Error("reply handler returned an error", log.Error(err))
+	}
+
+	return err
+}
@@ -0,0 +1,23 @@
+package msg
+
+import (
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
+)
+
+// ReplyDispatcherOption options for ReplyDispatcher
+type ReplyDispatcherOption func(consumer *ReplyDispatcher)
+
+// WithReplyDispatcherLogger is an option to set the log.Logger of the ReplyDispatcher
+func WithReplyDispatcherLogger(logger log.Logger) ReplyDispatcherOption {
+	return func(dispatcher *ReplyDispatcher) {
+		dispatcher.logger = logger
+	}
+}
+
+// WithReplyDispatcherMetrics is an option to set the metrics.Registry of the ReplyDispatcher
+func WithReplyDispatcherMetrics(registry metrics.Registry) ReplyDispatcherOption {
+	return func(dispatcher *ReplyDispatcher) {
+		dispatcher.registry = registry
+	}
+}
@@ -0,0 +1,175 @@
+package msg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/log/logmocks"
+	"github.com/stackus/edat/log/logtest"
+	"github.com/stackus/edat/msg"
+)
+
+func TestReplyDispatcher_ReceiveMessage(t *testing.T) {
+	type handler struct {
+		reply core.Reply
+		fn    msg.ReplyHandlerFunc
+	}
+	type fields struct {
+		handlers []handler
+		logger   log.Logger
+	}
+	type args struct {
+		ctx     context.Context
+		message msg.Message
+	}
+
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	core.RegisterReplies(coretest.Reply{})
+
+	tests := map[string]struct {
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		"Success": {
+			fields: fields{
+				handlers: []handler{
+					{
+						reply: coretest.Reply{},
+						fn: func(ctx context.Context, reply msg.ReceivedReply) error {
+							if reply.Outcome() != msg.ReplyOutcomeSuccess {
+								return fmt.Errorf("unexpected outcome `%s`", reply.Outcome())
+							}
+							if reply.CorrelationHeaders().Get("REPLY_SAGA_ID") != "saga-id" || len(reply.CorrelationHeaders()) != 1 {
+								return fmt.Errorf("unexpected correlation headers `%v`", reply.CorrelationHeaders())
+							}
+							return nil
+						},
+					},
+				},
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Sub", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(m)
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Debug", mock.AnythingOfType("string"), mock.Anything)
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+					msg.MessageReplyName:    coretest.Reply{}.ReplyName(),
+					msg.MessageReplyOutcome: msg.ReplyOutcomeSuccess,
+					"REPLY_SAGA_ID":         "saga-id",
+				})),
+			},
+			wantErr: false,
+		},
+		"HandlerError": {
+			fields: fields{
+				handlers: []handler{
+					{
+						reply: coretest.Reply{},
+						fn: func(ctx context.Context, reply msg.ReceivedReply) error {
+							return fmt.Errorf("handler-error")
+						},
+					},
+				},
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Sub", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(m)
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Debug", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Error", "reply handler returned an error", mock.Anything)
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+					msg.MessageReplyName:    coretest.Reply{}.ReplyName(),
+					msg.MessageReplyOutcome: msg.ReplyOutcomeFailure,
+				})),
+			},
+			wantErr: true,
+		},
+		"UnregisteredReply": {
+			fields: fields{
+				handlers: []handler{
+					{
+						reply: coretest.UnregisteredReply{},
+						fn: func(ctx context.Context, reply msg.ReceivedReply) error {
+							return nil
+						},
+					},
+				},
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Sub", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(m)
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Debug", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Error", "error decoding reply message payload", mock.Anything)
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+					msg.MessageReplyName:    coretest.UnregisteredReply{}.ReplyName(),
+					msg.MessageReplyOutcome: msg.ReplyOutcomeSuccess,
+				})),
+			},
+			wantErr: false,
+		},
+		"MissingReplyName": {
+			fields: fields{
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Error", "error reading reply name", mock.Anything)
+				}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{})),
+			},
+			wantErr: false,
+		},
+		"MissingOutcome": {
+			fields: fields{
+				handlers: []handler{
+					{
+						reply: coretest.Reply{},
+						fn: func(ctx context.Context, reply msg.ReceivedReply) error {
+							return nil
+						},
+					},
+				},
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Sub", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(m)
+					m.On("Trace", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Debug", mock.AnythingOfType("string"), mock.Anything)
+					m.On("Error", "error reading reply outcome", mock.Anything)
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				message: msg.NewMessage([]byte(`{"Value":""}`), msg.WithHeaders(map[string]string{
+					msg.MessageReplyName: coretest.Reply{}.ReplyName(),
+				})),
+			},
+			wantErr: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := msg.NewReplyDispatcher(msg.WithReplyDispatcherLogger(tt.fields.logger))
+			for _, handler := range tt.fields.handlers {
+				d.Handle(handler.reply, handler.fn)
+			}
+			if err := d.ReceiveMessage(tt.args.ctx, tt.args.message); (err != nil) != tt.wantErr {
+				t.Errorf("ReceiveMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mock.AssertExpectationsForObjects(t, tt.fields.logger)
+		})
+	}
+}
@@ -11,3 +11,9 @@ type Failure struct{}
 
 // ReplyName implements core.Reply.ReplyName
 func (Failure) ReplyName() string { return "edat.msg.Failure" }
+
+// Expired reply type for failure replies to commands that were received after they had expired
+type Expired struct{}
+
+// ReplyName implements core.Reply.ReplyName
+func (Expired) ReplyName() string { return "edat.msg.Expired" }
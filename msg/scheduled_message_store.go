@@ -0,0 +1,13 @@
+package msg
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduledMessageStore interface for holding delayed messages until they are due to be delivered
+type ScheduledMessageStore interface {
+	Schedule(ctx context.Context, message Message, deliverAt time.Time) error
+	FetchDue(ctx context.Context, dueBy time.Time, limit int) ([]Message, error)
+	Remove(ctx context.Context, messageIDs []string) error
+}
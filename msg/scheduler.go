@@ -0,0 +1,189 @@
+package msg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stackus/edat/log"
+)
+
+// Scheduler holds delayed messages in a ScheduledMessageStore and releases them to a publisher when they are due
+//
+// The scheduler is used as a Publisher middleware and should be the last middleware added so that
+// messages are stored in the exact form they will be sent in. Due messages have already been processed by the
+// middlewares, and are released, without their deliver at header, straight to the producer of the Publisher so that
+// they are included in the publisher metrics. Publishers other than a *Publisher are given the released messages
+// with Publish
+//  publisher := msg.NewPublisher(producer)
+//  scheduler := msg.NewScheduler(store, publisher)
+//  publisher.Use(scheduler.PublisherMiddleware)
+//  publisher.PublishCommand(ctx, replyChannel, cmd, msg.WithDelay(30*time.Minute))
+type Scheduler struct {
+	store              ScheduledMessageStore
+	publisher          MessagePublisher
+	messagesPerPolling int
+	pollingInterval    time.Duration
+	logger             log.Logger
+	stopping           chan struct{}
+	close              sync.Once
+}
+
+// NewScheduler constructs a new Scheduler
+func NewScheduler(store ScheduledMessageStore, publisher MessagePublisher, options ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		store:              store,
+		publisher:          publisher,
+		messagesPerPolling: DefaultSchedulerMessagesPerPolling,
+		pollingInterval:    DefaultSchedulerPollingInterval,
+		logger:             log.DefaultLogger,
+		stopping:           make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.logger.Trace("msg.Scheduler constructed")
+
+	return s
+}
+
+// PublisherMiddleware is a Publisher middleware that stores messages that should be delivered in the future
+func (s *Scheduler) PublisherMiddleware(next MessagePublisher) MessagePublisher {
+	return PublishMessageFunc(func(ctx context.Context, message Message) error {
+		deliverAt, err := messageDeliverAt(message)
+		if err != nil {
+			s.logger.Error("error reading deliver at time", log.String("MessageID", message.ID()), log.Error(err))
+			return err
+		}
+
+		if deliverAt.IsZero() || !deliverAt.After(time.Now()) {
+			return next.Publish(ctx, message)
+		}
+
+		logger := s.logger.Sub(
+			log.String("MessageID", message.ID()),
+			log.Duration("Delay", time.Until(deliverAt)),
+		)
+
+		logger.Trace("scheduling message")
+
+		err = s.store.Schedule(ctx, message, deliverAt)
+		if err != nil {
+			logger.Error("error scheduling message", log.Error(err))
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Cancel removes a scheduled message before it has been delivered
+func (s *Scheduler) Cancel(ctx context.Context, messageID string) error {
+	s.logger.Trace("cancelling scheduled message", log.String("MessageID", messageID))
+
+	return s.store.Remove(ctx, []string{messageID})
+}
+
+// Start begins releasing due messages to the publisher
+func (s *Scheduler) Start(ctx context.Context) error {
+	pollingTimer := time.NewTimer(0)
+	defer pollingTimer.Stop()
+
+	s.logger.Trace("scheduler started")
+
+	for {
+		messages, err := s.store.FetchDue(ctx, time.Now(), s.messagesPerPolling)
+		if err != nil {
+			s.logger.Error("error fetching scheduled messages", log.Error(err))
+			return err
+		}
+
+		if len(messages) > 0 {
+			err = s.release(ctx, messages)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !pollingTimer.Stop() {
+			select {
+			case <-pollingTimer.C:
+			default:
+			}
+		}
+
+		pollingTimer.Reset(s.pollingInterval)
+
+		select {
+		case <-s.stopping:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-pollingTimer.C:
+		}
+	}
+}
+
+// Stop stops the scheduler
+func (s *Scheduler) Stop(context.Context) error {
+	s.close.Do(func() {
+		close(s.stopping)
+		s.logger.Trace("scheduler stopped")
+	})
+
+	return nil
+}
+
+func (s *Scheduler) release(ctx context.Context, messages []Message) error {
+	s.logger.Trace("releasing scheduled messages", log.Int("MessageCount", len(messages)))
+
+	// the middlewares have already run for the stored messages; a Publisher would run them a second time
+	release := s.publisher.Publish
+	if publisher, ok := s.publisher.(*Publisher); ok {
+		release = publisher.send
+	}
+
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		// released messages are sent right away and must not be scheduled again; the stored message is left as is
+		headers := make(Headers, len(message.Headers()))
+		for key, value := range message.Headers() {
+			if key != MessageDeliverAt {
+				headers[key] = value
+			}
+		}
+
+		err := release(ctx, NewMessage(message.Payload(), WithMessageID(message.ID()), WithHeaders(headers)))
+		if err != nil {
+			s.logger.Error("error releasing scheduled message",
+				log.String("MessageID", message.ID()),
+				log.String("Destination", message.Headers().Get(MessageChannel)),
+				log.Error(err),
+			)
+			return err
+		}
+
+		ids = append(ids, message.ID())
+	}
+
+	err := s.store.Remove(ctx, ids)
+	if err != nil {
+		s.logger.Error("error removing released messages", log.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func messageDeliverAt(message Message) (time.Time, error) {
+	value := message.Headers().Get(MessageDeliverAt)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
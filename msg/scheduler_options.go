@@ -0,0 +1,31 @@
+package msg
+
+import (
+	"time"
+
+	"github.com/stackus/edat/log"
+)
+
+// SchedulerOption options for Scheduler
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerMessagesPerPolling sets the number of due messages to fetch for Scheduler
+func WithSchedulerMessagesPerPolling(messagesPerPolling int) SchedulerOption {
+	return func(scheduler *Scheduler) {
+		scheduler.messagesPerPolling = messagesPerPolling
+	}
+}
+
+// WithSchedulerPollingInterval sets the interval between attempts to fetch due messages for Scheduler
+func WithSchedulerPollingInterval(pollingInterval time.Duration) SchedulerOption {
+	return func(scheduler *Scheduler) {
+		scheduler.pollingInterval = pollingInterval
+	}
+}
+
+// WithSchedulerLogger is an option to set the log.Logger of the Scheduler
+func WithSchedulerLogger(logger log.Logger) SchedulerOption {
+	return func(scheduler *Scheduler) {
+		scheduler.logger = logger
+	}
+}
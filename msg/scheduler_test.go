@@ -0,0 +1,173 @@
+package msg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/msg/msgmocks"
+	"github.com/stackus/edat/msg/msgtest"
+)
+
+func TestScheduler_PublisherMiddleware(t *testing.T) {
+	type fields struct {
+		store *msgmocks.ScheduledMessageStore
+	}
+	type args struct {
+		ctx     context.Context
+		message msg.Message
+	}
+	tests := map[string]struct {
+		fields        fields
+		args          args
+		wantPublished bool
+		wantErr       bool
+	}{
+		"Immediate": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`)),
+			},
+			wantPublished: true,
+			wantErr:       false,
+		},
+		"PastDue": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithDeliverAt(time.Now().Add(-time.Minute))),
+			},
+			wantPublished: true,
+			wantErr:       false,
+		},
+		"Delayed": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {
+					m.On("Schedule", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
+				}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithDelay(time.Minute)),
+			},
+			wantPublished: false,
+			wantErr:       false,
+		},
+		"StoreError": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {
+					m.On("Schedule", mock.Anything, mock.Anything, mock.AnythingOfType("time.Time")).Return(fmt.Errorf("store-error"))
+				}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithDelay(time.Minute)),
+			},
+			wantPublished: false,
+			wantErr:       true,
+		},
+		"InvalidDeliverAt": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {}),
+			},
+			args: args{
+				ctx:     context.Background(),
+				message: msg.NewMessage([]byte(`{}`), msg.WithHeaders(map[string]string{msg.MessageDeliverAt: "tomorrow"})),
+			},
+			wantPublished: false,
+			wantErr:       true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := msg.NewScheduler(tt.fields.store, msg.NewPublisher(msgtest.MockProducer(func(m *msgmocks.Producer) {})))
+			published := false
+			publisher := s.PublisherMiddleware(msg.PublishMessageFunc(func(context.Context, msg.Message) error {
+				published = true
+				return nil
+			}))
+			if err := publisher.Publish(tt.args.ctx, tt.args.message); (err != nil) != tt.wantErr {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if published != tt.wantPublished {
+				t.Errorf("Publish() published = %v, want %v", published, tt.wantPublished)
+			}
+			mock.AssertExpectationsForObjects(t, tt.fields.store)
+		})
+	}
+}
+
+func TestScheduler_Start(t *testing.T) {
+	type fields struct {
+		store    *msgmocks.ScheduledMessageStore
+		producer *msgmocks.Producer
+	}
+
+	dueMsg := msg.NewMessage([]byte(`{}`), msg.WithDestinationChannel("channel"), msg.WithDeliverAt(time.Now()))
+
+	// released messages are sent to the producer without their deliver at header and without running the publisher
+	// middlewares again; the stored message is not changed
+	released := mock.MatchedBy(func(message msg.Message) bool {
+		return message.ID() == dueMsg.ID() && !message.Headers().Has(msg.MessageDeliverAt) && !message.Headers().Has(msg.MessageDate) &&
+			dueMsg.Headers().Has(msg.MessageDeliverAt)
+	})
+
+	tests := map[string]struct {
+		fields  fields
+		wantErr bool
+	}{
+		"Success": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {
+					m.On("FetchDue", mock.Anything, mock.Anything, msg.DefaultSchedulerMessagesPerPolling).Return([]msg.Message{dueMsg}, nil).Once()
+					m.On("FetchDue", mock.Anything, mock.Anything, msg.DefaultSchedulerMessagesPerPolling).Return([]msg.Message{}, nil)
+					m.On("Remove", mock.Anything, []string{dueMsg.ID()}).Return(nil).Once()
+				}),
+				producer: msgtest.MockProducer(func(m *msgmocks.Producer) {
+					m.On("Send", mock.Anything, "channel", released).Return(nil).Once()
+				}),
+			},
+			wantErr: false,
+		},
+		"ProducerError": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {
+					m.On("FetchDue", mock.Anything, mock.Anything, msg.DefaultSchedulerMessagesPerPolling).Return([]msg.Message{dueMsg}, nil).Once()
+				}),
+				producer: msgtest.MockProducer(func(m *msgmocks.Producer) {
+					m.On("Send", mock.Anything, "channel", released).Return(fmt.Errorf("producer-error")).Once()
+				}),
+			},
+			wantErr: true,
+		},
+		"StoreError": {
+			fields: fields{
+				store: msgtest.MockScheduledMessageStore(func(m *msgmocks.ScheduledMessageStore) {
+					m.On("FetchDue", mock.Anything, mock.Anything, msg.DefaultSchedulerMessagesPerPolling).Return(nil, fmt.Errorf("store-error")).Once()
+				}),
+				producer: msgtest.MockProducer(func(m *msgmocks.Producer) {}),
+			},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := msg.NewScheduler(tt.fields.store, msg.NewPublisher(tt.fields.producer), msg.WithSchedulerPollingInterval(time.Millisecond))
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := s.Start(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mock.AssertExpectationsForObjects(t, tt.fields.store, tt.fields.producer)
+		})
+	}
+}
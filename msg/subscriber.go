@@ -2,12 +2,16 @@ package msg
 
 import (
 	"context"
+	"fmt"
+	"path"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // MessageSubscriber interface
@@ -17,13 +21,19 @@ type MessageSubscriber interface {
 
 // Subscriber receives domain events, commands, and replies from the consumer
 type Subscriber struct {
-	consumer     Consumer
-	logger       log.Logger
-	middlewares  []func(MessageReceiver) MessageReceiver
-	receivers    map[string][]MessageReceiver
-	stopping     chan struct{}
-	subscriberWg sync.WaitGroup
-	close        sync.Once
+	consumer       Consumer
+	logger         log.Logger
+	tracer         core.Tracer
+	registry       metrics.Registry
+	received       metrics.Counter
+	errors         metrics.Counter
+	duration       metrics.Histogram
+	middlewares    []func(MessageReceiver) MessageReceiver
+	receivers      map[string][]MessageReceiver
+	channelRefresh time.Duration
+	stopping       chan struct{}
+	subscriberWg   sync.WaitGroup
+	close          sync.Once
 }
 
 // NewSubscriber constructs a new Subscriber
@@ -33,12 +43,18 @@ func NewSubscriber(consumer Consumer, options ...SubscriberOption) *Subscriber {
 		receivers: make(map[string][]MessageReceiver),
 		stopping:  make(chan struct{}),
 		logger:    log.DefaultLogger,
+		tracer:    core.DefaultTracer,
+		registry:  metrics.DefaultRegistry,
 	}
 
 	for _, option := range options {
 		option(s)
 	}
 
+	s.received = s.registry.Counter(metrics.MessagesReceived, "Number of messages received")
+	s.errors = s.registry.Counter(metrics.MessageReceiveErrors, "Number of errors returned by receivers")
+	s.duration = s.registry.Histogram(metrics.MessageReceiveDuration, "Time spent in receivers in seconds", metrics.DefaultBuckets)
+
 	s.logger.Trace("msg.Subscriber constructed")
 
 	return s
@@ -54,7 +70,15 @@ func (s *Subscriber) Use(mws ...func(MessageReceiver) MessageReceiver) {
 }
 
 // Subscribe connects the receiver with messages from the channel on the consumer
+//
+// The channel may also be a pattern, e.g. "orders.*" or "*", using the syntax of path.Match. Patterns
+// require a consumer that implements either ChannelLister or PatternConsumer
 func (s *Subscriber) Subscribe(channel string, receiver MessageReceiver) {
+	if IsChannelPattern(channel) {
+		if _, err := path.Match(channel, ""); err != nil {
+			panic(fmt.Sprintf("invalid channel pattern `%s`", channel))
+		}
+	}
 	if _, exists := s.receivers[channel]; !exists {
 		s.receivers[channel] = []MessageReceiver{}
 	}
@@ -63,7 +87,30 @@ func (s *Subscriber) Subscribe(channel string, receiver MessageReceiver) {
 }
 
 // Start begins listening to all of the channels sending received messages into them
+//
+// Channel patterns are resolved against the channels reported by a ChannelLister when the subscriber is started.
+// Channels that are created later are only listened to when a refresh interval has been set using
+// WithSubscriberChannelRefresh; a PatternConsumer receives every matching channel without a refresh
 func (s *Subscriber) Start(ctx context.Context) error {
+	channels, patterns := s.subscriptions()
+
+	lister, isLister := s.consumer.(ChannelLister)
+	if len(patterns) > 0 && isLister {
+		names, err := lister.Channels(ctx)
+		if err != nil {
+			s.logger.Error("error resolving channel patterns", log.Error(err))
+			return err
+		}
+
+		for name, receivers := range s.matchPatterns(patterns, names, nil) {
+			channels[name] = append(channels[name], receivers...)
+		}
+	} else if _, ok := s.consumer.(PatternConsumer); len(patterns) > 0 && !ok {
+		err := fmt.Errorf("consumer is unable to subscribe to channel patterns")
+		s.logger.Error("error resolving channel patterns", log.Error(err))
+		return err
+	}
+
 	cCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	group, gCtx := errgroup.WithContext(cCtx)
@@ -78,41 +125,12 @@ func (s *Subscriber) Start(ctx context.Context) error {
 		return nil
 	})
 
-	for c, r := range s.receivers {
-		// reassign to avoid issues with anonymous func
-		channel := c
-		receivers := r
-
+	listen := func(channel string, receivers []MessageReceiver) {
 		s.subscriberWg.Add(1)
 
 		group.Go(func() error {
 			defer s.subscriberWg.Done()
-			receiveMessageFunc := func(mCtx context.Context, message Message) error {
-				mCtx = core.SetRequestContext(
-					mCtx,
-					message.ID(),
-					message.Headers().Get(MessageCorrelationID),
-					message.Headers().Get(MessageCausationID),
-				)
-
-				s.logger.Trace("received message",
-					log.String("MessageID", message.ID()),
-					log.String("CorrelationID", message.Headers().Get(MessageCorrelationID)),
-					log.String("CausationID", message.Headers().Get(MessageCausationID)),
-					log.Int("PayloadSize", len(message.Payload())),
-				)
-
-				rGroup, rCtx := errgroup.WithContext(mCtx)
-				for _, r2 := range receivers {
-					receiver := r2
-					rGroup.Go(func() error {
-						return receiver.ReceiveMessage(rCtx, message)
-					})
-				}
-
-				return rGroup.Wait()
-			}
-			err := s.consumer.Listen(gCtx, channel, receiveMessageFunc)
+			err := s.consumer.Listen(gCtx, channel, s.receiveMessageFunc(receivers))
 			if err != nil {
 				s.logger.Error("consumer stopped and returned an error", log.Error(err))
 				return err
@@ -122,6 +140,41 @@ func (s *Subscriber) Start(ctx context.Context) error {
 		})
 	}
 
+	for channel, receivers := range channels {
+		listen(channel, receivers)
+	}
+
+	switch {
+	case len(patterns) == 0:
+	case isLister && s.channelRefresh > 0:
+		// the refresh is counted as a receiver so that Stop also waits for any channels it starts listening to
+		s.subscriberWg.Add(1)
+
+		group.Go(func() error {
+			defer s.subscriberWg.Done()
+			return s.refreshChannels(gCtx, lister, patterns, channels, listen)
+		})
+	case !isLister:
+		for p, r := range patterns {
+			// reassign to avoid issues with anonymous func
+			pattern := p
+			receivers := r
+
+			s.subscriberWg.Add(1)
+
+			group.Go(func() error {
+				defer s.subscriberWg.Done()
+				err := s.consumer.(PatternConsumer).ListenPattern(gCtx, pattern, s.receiveMessageFunc(receivers))
+				if err != nil {
+					s.logger.Error("consumer stopped and returned an error", log.Error(err))
+					return err
+				}
+
+				return nil
+			})
+		}
+	}
+
 	return group.Wait()
 }
 
@@ -160,3 +213,117 @@ func (s *Subscriber) chain(receiver MessageReceiver) MessageReceiver {
 
 	return r
 }
+
+// subscriptions splits the subscriptions into the exact channels and the channel patterns
+func (s *Subscriber) subscriptions() (map[string][]MessageReceiver, map[string][]MessageReceiver) {
+	channels := make(map[string][]MessageReceiver)
+	patterns := make(map[string][]MessageReceiver)
+
+	for channel, receivers := range s.receivers {
+		if IsChannelPattern(channel) {
+			patterns[channel] = receivers
+			continue
+		}
+		channels[channel] = append(channels[channel], receivers...)
+	}
+
+	return channels, patterns
+}
+
+// matchPatterns returns the receivers of the patterns for each of the named channels that are not being skipped
+func (s *Subscriber) matchPatterns(patterns map[string][]MessageReceiver, names []string, skip map[string][]MessageReceiver) map[string][]MessageReceiver {
+	matched := make(map[string][]MessageReceiver)
+
+	for pattern, receivers := range patterns {
+		for _, name := range names {
+			if _, exists := skip[name]; exists {
+				continue
+			}
+			if MatchChannel(pattern, name) {
+				s.logger.Trace("resolved channel pattern", log.String("Pattern", pattern), log.String("Channel", name))
+				matched[name] = append(matched[name], receivers...)
+			}
+		}
+	}
+
+	return matched
+}
+
+// refreshChannels periodically resolves the patterns again and listens to any new channels that match
+func (s *Subscriber) refreshChannels(ctx context.Context, lister ChannelLister, patterns, channels map[string][]MessageReceiver, listen func(string, []MessageReceiver)) error {
+	ticker := time.NewTicker(s.channelRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		names, err := lister.Channels(ctx)
+		if err != nil {
+			// the channels already being listened to are unaffected; try again on the next refresh
+			s.logger.Error("error refreshing channel patterns", log.Error(err))
+			continue
+		}
+
+		for name, receivers := range s.matchPatterns(patterns, names, channels) {
+			channels[name] = receivers
+			listen(name, receivers)
+		}
+	}
+}
+
+func (s *Subscriber) receiveMessageFunc(receivers []MessageReceiver) ReceiveMessageFunc {
+	return func(mCtx context.Context, message Message) error {
+		mCtx = core.SetRequestContext(
+			mCtx,
+			message.ID(),
+			message.Headers().Get(MessageCorrelationID),
+			message.Headers().Get(MessageCausationID),
+		)
+
+		mCtx = core.ContextWithTraceParent(
+			mCtx,
+			message.Headers().Get(MessageTraceParent),
+			message.Headers().Get(MessageTraceState),
+		)
+
+		channel := message.Headers().Get(MessageChannel)
+
+		mCtx, span := s.tracer.Start(mCtx, "receive "+channel, core.SpanKindConsumer)
+		defer span.End()
+
+		span.SetAttribute("MessageID", message.ID())
+		span.SetAttribute("Channel", channel)
+
+		s.logger.Trace("received message",
+			log.String("MessageID", message.ID()),
+			log.String("CorrelationID", message.Headers().Get(MessageCorrelationID)),
+			log.String("CausationID", message.Headers().Get(MessageCausationID)),
+			log.Int("PayloadSize", len(message.Payload())),
+		)
+
+		started := time.Now()
+		rGroup, rCtx := errgroup.WithContext(mCtx)
+		for _, r := range receivers {
+			receiver := r
+			rGroup.Go(func() error {
+				return receiver.ReceiveMessage(rCtx, message)
+			})
+		}
+
+		err := rGroup.Wait()
+
+		labels := metrics.Labels{"channel": channel}
+		s.received.Inc(labels)
+		s.duration.Observe(time.Since(started).Seconds(), labels)
+		if err != nil {
+			s.errors.Inc(labels)
+			span.RecordError(err)
+		}
+
+		return err
+	}
+}
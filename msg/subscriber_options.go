@@ -1,7 +1,11 @@
 package msg
 
 import (
+	"time"
+
+	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // SubscriberOption options for MessageConsumers
@@ -13,3 +17,27 @@ func WithSubscriberLogger(logger log.Logger) SubscriberOption {
 		subscriber.logger = logger
 	}
 }
+
+// WithSubscriberTracer is an option to set the core.Tracer of the Subscriber
+func WithSubscriberTracer(tracer core.Tracer) SubscriberOption {
+	return func(subscriber *Subscriber) {
+		subscriber.tracer = tracer
+	}
+}
+
+// WithSubscriberMetrics is an option to set the metrics.Registry of the Subscriber
+func WithSubscriberMetrics(registry metrics.Registry) SubscriberOption {
+	return func(subscriber *Subscriber) {
+		subscriber.registry = registry
+	}
+}
+
+// WithSubscriberChannelRefresh is an option to set how often channel patterns are resolved again for the Subscriber
+//
+// Channels created after the Subscriber has started are only listened to when a refresh interval is set. The
+// refresh is only used with consumers that implement ChannelLister
+func WithSubscriberChannelRefresh(interval time.Duration) SubscriberOption {
+	return func(subscriber *Subscriber) {
+		subscriber.channelRefresh = interval
+	}
+}
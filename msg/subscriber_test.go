@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/mock"
 
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
 	"github.com/stackus/edat/log"
 	"github.com/stackus/edat/log/logmocks"
 	"github.com/stackus/edat/log/logtest"
@@ -214,6 +216,23 @@ func TestSubscriber_Subscribe(t *testing.T) {
 			},
 			wantPanic: false,
 		},
+		"InvalidPattern": {
+			fields: fields{
+				consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {}),
+				logger: logtest.MockLogger(func(m *logmocks.Logger) {
+					m.On("Trace", mock.Anything, mock.Anything)
+				}),
+			},
+			args: args{
+				receivers: []receivers{
+					{
+						channel:  "orders.[",
+						receiver: msgtest.MockMessageReceiver(func(m *msgmocks.MessageReceiver) {}),
+					},
+				},
+			},
+			wantPanic: true,
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -230,3 +249,175 @@ func TestSubscriber_Subscribe(t *testing.T) {
 		})
 	}
 }
+
+type channelListerConsumer struct {
+	*msgmocks.Consumer
+	*msgmocks.ChannelLister
+}
+
+type patternConsumer struct {
+	*msgmocks.Consumer
+	*msgmocks.PatternConsumer
+}
+
+func TestSubscriber_StartPatterns(t *testing.T) {
+	tests := map[string]struct {
+		consumer        *msgmocks.Consumer
+		channelLister   *msgmocks.ChannelLister
+		patternConsumer *msgmocks.PatternConsumer
+		channels        []string
+		refresh         time.Duration
+		wantErr         bool
+	}{
+		"ChannelLister": {
+			consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {
+				m.On("Listen", mock.Anything, "orders.created", mock.Anything).Return(nil).Once()
+				m.On("Listen", mock.Anything, "orders.updated", mock.Anything).Return(nil).Once()
+			}),
+			channelLister: msgtest.MockChannelLister(func(m *msgmocks.ChannelLister) {
+				m.On("Channels", mock.Anything).Return([]string{"orders.created", "orders.updated", "customers.created"}, nil)
+			}),
+			channels: []string{"orders.*", "orders.created"},
+			wantErr:  false,
+		},
+		"ChannelListerRefresh": {
+			consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {
+				m.On("Listen", mock.Anything, "orders.created", mock.Anything).Return(nil).Once()
+				m.On("Listen", mock.Anything, "orders.shipped", mock.Anything).Return(nil).Once()
+			}),
+			channelLister: msgtest.MockChannelLister(func(m *msgmocks.ChannelLister) {
+				m.On("Channels", mock.Anything).Return([]string{"orders.created"}, nil).Once()
+				m.On("Channels", mock.Anything).Return([]string{"orders.created", "orders.shipped"}, nil)
+			}),
+			channels: []string{"orders.*"},
+			refresh:  time.Millisecond,
+			wantErr:  false,
+		},
+		"ChannelListerError": {
+			consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {}),
+			channelLister: msgtest.MockChannelLister(func(m *msgmocks.ChannelLister) {
+				m.On("Channels", mock.Anything).Return(nil, fmt.Errorf("lister-error"))
+			}),
+			channels: []string{"orders.*"},
+			wantErr:  true,
+		},
+		"PatternConsumer": {
+			consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {
+				m.On("Listen", mock.Anything, "orders.created", mock.Anything).Return(nil).Once()
+			}),
+			patternConsumer: msgtest.MockPatternConsumer(func(m *msgmocks.PatternConsumer) {
+				m.On("ListenPattern", mock.Anything, "*", mock.Anything).Return(nil).Once()
+			}),
+			channels: []string{"*", "orders.created"},
+			wantErr:  false,
+		},
+		"Unsupported": {
+			consumer: msgtest.MockConsumer(func(m *msgmocks.Consumer) {}),
+			channels: []string{"orders.*"},
+			wantErr:  true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var consumer msg.Consumer = tt.consumer
+			if tt.channelLister != nil {
+				consumer = channelListerConsumer{tt.consumer, tt.channelLister}
+			}
+			if tt.patternConsumer != nil {
+				consumer = patternConsumer{tt.consumer, tt.patternConsumer}
+			}
+			s := msg.NewSubscriber(consumer, msg.WithSubscriberChannelRefresh(tt.refresh))
+			for _, channel := range tt.channels {
+				s.Subscribe(channel, msgtest.MockMessageReceiver(func(m *msgmocks.MessageReceiver) {}))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			if err := s.Start(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mock.AssertExpectationsForObjects(t, tt.consumer)
+			if tt.channelLister != nil {
+				mock.AssertExpectationsForObjects(t, tt.channelLister)
+			}
+			if tt.patternConsumer != nil {
+				mock.AssertExpectationsForObjects(t, tt.patternConsumer)
+			}
+		})
+	}
+}
+
+func TestMatchChannel(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		channel string
+		want    bool
+	}{
+		"Exact":          {pattern: "orders", channel: "orders", want: true},
+		"Wildcard":       {pattern: "*", channel: "orders", want: true},
+		"Prefix":         {pattern: "orders.*", channel: "orders.created", want: true},
+		"PrefixMismatch": {pattern: "orders.*", channel: "customers.created", want: false},
+		"Malformed":      {pattern: "orders.[", channel: "orders.created", want: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := msg.MatchChannel(tt.pattern, tt.channel); got != tt.want {
+				t.Errorf("MatchChannel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriber_TraceContext(t *testing.T) {
+	tracer := coretest.NewRecordingTracer()
+	parent := core.SpanContext{TraceID: core.NewTraceID(), SpanID: core.NewSpanID(), TraceFlags: core.TraceFlagsSampled}
+
+	var published msg.Message
+	producer := msgtest.MockProducer(func(m *msgmocks.Producer) {
+		m.On("Send", mock.Anything, "channel", mock.Anything).Run(func(args mock.Arguments) {
+			published = args.Get(2).(msg.Message)
+		}).Return(nil)
+	})
+
+	p := msg.NewPublisher(producer, msg.WithPublisherTracer(tracer))
+	err := p.Publish(core.ContextWithSpanContext(context.Background(), parent), msg.NewMessage([]byte(`{}`), msg.WithDestinationChannel("channel")))
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	consumer := msgtest.MockConsumer(func(m *msgmocks.Consumer) {
+		m.On("Listen", mock.Anything, "channel", mock.Anything).Return(func(ctx context.Context, _ string, fn msg.ReceiveMessageFunc) error {
+			return fn(ctx, published)
+		})
+	})
+
+	var received core.SpanContext
+	s := msg.NewSubscriber(consumer, msg.WithSubscriberTracer(tracer))
+	s.Subscribe("channel", msg.ReceiveMessageFunc(func(ctx context.Context, _ msg.Message) error {
+		received = core.GetSpanContext(ctx)
+		return nil
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Spans() got = %d spans, want 2", len(spans))
+	}
+	publishSpan, receiveSpan := spans[0], spans[1]
+	if publishSpan.Kind != core.SpanKindProducer || publishSpan.Parent != parent {
+		t.Errorf("publish span got = %v, want a producer span with parent %v", publishSpan, parent)
+	}
+	if published.Headers().Get(msg.MessageTraceParent) != publishSpan.Context.TraceParent() {
+		t.Errorf("traceparent header got = %v, want %v", published.Headers().Get(msg.MessageTraceParent), publishSpan.Context.TraceParent())
+	}
+	if receiveSpan.Kind != core.SpanKindConsumer || receiveSpan.Parent.SpanID != publishSpan.Context.SpanID {
+		t.Errorf("receive span got = %v, want a consumer span with parent %v", receiveSpan, publishSpan.Context)
+	}
+	if received != receiveSpan.Context || received.TraceID != parent.TraceID {
+		t.Errorf("receiver span context got = %v, want %v", received, receiveSpan.Context)
+	}
+}
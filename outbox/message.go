@@ -20,6 +20,21 @@ type message struct {
 	payload []byte
 }
 
+// FromMessage converts a msg.Message into the temporary form of a Producers msg.Message
+func FromMessage(destination string, message msg.Message) (Message, error) {
+	headers, err := json.Marshal(message.Headers())
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		MessageID:   message.ID(),
+		Destination: destination,
+		Payload:     message.Payload(),
+		Headers:     headers,
+	}, nil
+}
+
 // ToMessage converts this form back to msg.Message or returns an error when headers cannot be unmarshalled
 func (m Message) ToMessage() (msg.Message, error) {
 	var headers map[string]string
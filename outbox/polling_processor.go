@@ -8,6 +8,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 	"github.com/stackus/edat/msg"
 	"github.com/stackus/edat/retry"
 )
@@ -22,6 +23,10 @@ type PollingProcessor struct {
 	purgeInterval      time.Duration
 	retryer            retry.Retryer
 	logger             log.Logger
+	registry           metrics.Registry
+	processed          metrics.Counter
+	errors             metrics.Counter
+	lag                metrics.Histogram
 	stopping           chan struct{}
 	close              sync.Once
 }
@@ -39,6 +44,7 @@ func NewPollingProcessor(in MessageStore, out msg.MessagePublisher, options ...P
 		purgeInterval:      DefaultPurgeInterval,
 		retryer:            DefaultRetryer,
 		logger:             log.DefaultLogger,
+		registry:           metrics.DefaultRegistry,
 		stopping:           make(chan struct{}),
 	}
 
@@ -46,6 +52,10 @@ func NewPollingProcessor(in MessageStore, out msg.MessagePublisher, options ...P
 		option(p)
 	}
 
+	p.processed = p.registry.Counter(metrics.OutboxMessagesProcessed, "Number of outbox messages published")
+	p.errors = p.registry.Counter(metrics.OutboxProcessErrors, "Number of errors publishing outbox messages")
+	p.lag = p.registry.Histogram(metrics.OutboxLag, "Time between a message being published into and out of the outbox in seconds", metrics.DefaultBuckets)
+
 	p.logger.Trace("outbox.PollingProcessor constructed")
 
 	return p
@@ -119,13 +129,24 @@ func (p *PollingProcessor) processMessages(ctx context.Context) error {
 		if len(messages) > 0 {
 			p.logger.Trace("processing messages", log.Int("MessageCount", len(messages)))
 			ids := make([]string, 0, len(messages))
-			for _, message := range messages {
-				err := p.processMessage(ctx, message)
+			if batcher, ok := p.out.(msg.BatchMessagePublisher); ok {
+				err = p.processBatch(ctx, batcher, messages)
 				if err != nil {
 					return err
 				}
 
-				ids = append(ids, message.MessageID)
+				for _, message := range messages {
+					ids = append(ids, message.MessageID)
+				}
+			} else {
+				for _, message := range messages {
+					err := p.processMessage(ctx, message)
+					if err != nil {
+						return err
+					}
+
+					ids = append(ids, message.MessageID)
+				}
 			}
 
 			err = p.retryer.Retry(ctx, func() error {
@@ -170,16 +191,68 @@ func (p *PollingProcessor) processMessage(ctx context.Context, message Message)
 		// TODO this has potential to halt processing; systems need to be in place to fix or address
 		return err
 	}
+	// the publisher will replace the date the message was published into the outbox
+	date := outgoingMsg.Headers().Get(msg.MessageDate)
+
 	err = p.out.Publish(ctx, outgoingMsg)
 	if err != nil {
+		p.errors.Inc(metrics.Labels{"channel": message.Destination})
 		logger.Error("error publishing message", log.Error(err))
 		// TODO this has potential to halt processing; systems need to be in place to fix or address
 		return err
 	}
 
+	p.observe(message.Destination, date)
+
+	return nil
+}
+
+func (p *PollingProcessor) processBatch(ctx context.Context, batcher msg.BatchMessagePublisher, messages []Message) error {
+	outgoingMsgs := make([]msg.Message, 0, len(messages))
+	dates := make([]string, 0, len(messages))
+
+	for _, message := range messages {
+		outgoingMsg, err := message.ToMessage()
+		if err != nil {
+			p.logger.Error("error with transforming stored message", log.String("MessageID", message.MessageID), log.Error(err))
+			// TODO this has potential to halt processing; systems need to be in place to fix or address
+			return err
+		}
+
+		outgoingMsgs = append(outgoingMsgs, outgoingMsg)
+		dates = append(dates, outgoingMsg.Headers().Get(msg.MessageDate))
+	}
+
+	err := batcher.PublishBatch(ctx, outgoingMsgs)
+	if err != nil {
+		for _, message := range messages {
+			p.errors.Inc(metrics.Labels{"channel": message.Destination})
+		}
+		p.logger.Error("error publishing message batch", log.Error(err))
+		// TODO this has potential to halt processing; systems need to be in place to fix or address
+		return err
+	}
+
+	for i, message := range messages {
+		p.observe(message.Destination, dates[i])
+	}
+
 	return nil
 }
 
+// observe records a published message and how long it had been waiting in the outbox
+//
+// Messages are dated by the msg.Publisher when they are published into the outbox
+func (p *PollingProcessor) observe(destination, date string) {
+	labels := metrics.Labels{"channel": destination}
+
+	p.processed.Inc(labels)
+
+	if published, err := time.Parse(time.RFC3339, date); err == nil {
+		p.lag.Observe(time.Since(published).Seconds(), labels)
+	}
+}
+
 func (p *PollingProcessor) purgePublished(ctx context.Context) error {
 	purgeTimer := time.NewTimer(0)
 
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 	"github.com/stackus/edat/retry"
 )
 
@@ -51,3 +52,10 @@ func WithPollingProcessorLogger(logger log.Logger) PollingProcessorOption {
 		processor.logger = logger
 	}
 }
+
+// WithPollingProcessorMetrics sets the metrics.Registry for PollingProcessor
+func WithPollingProcessorMetrics(registry metrics.Registry) PollingProcessorOption {
+	return func(processor *PollingProcessor) {
+		processor.registry = registry
+	}
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/stackus/edat/msg"
+)
+
+// DelayedMessageStore interface for stores that hold delayed messages in the outbox Message form
+//
+// This package does not provide an implementation; infrastructure packages implement it next to their
+// MessageStore. Stores that share the transaction of the outbox allow messages to be scheduled atomically with
+// other changes made by an application
+type DelayedMessageStore interface {
+	SaveDelayed(ctx context.Context, message Message, deliverAt time.Time) error
+	FetchDue(ctx context.Context, dueBy time.Time, limit int) ([]Message, error)
+	DeleteDelayed(ctx context.Context, messageIDs []string) error
+}
+
+// ScheduledMessageStore adapts a DelayedMessageStore to msg.ScheduledMessageStore
+type ScheduledMessageStore struct {
+	store DelayedMessageStore
+}
+
+var _ msg.ScheduledMessageStore = (*ScheduledMessageStore)(nil)
+
+// NewScheduledMessageStore constructs a new ScheduledMessageStore
+func NewScheduledMessageStore(store DelayedMessageStore) *ScheduledMessageStore {
+	return &ScheduledMessageStore{
+		store: store,
+	}
+}
+
+// Schedule implements msg.ScheduledMessageStore.Schedule
+func (s *ScheduledMessageStore) Schedule(ctx context.Context, message msg.Message, deliverAt time.Time) error {
+	outboxMsg, err := FromMessage(message.Headers().Get(msg.MessageChannel), message)
+	if err != nil {
+		return err
+	}
+
+	return s.store.SaveDelayed(ctx, outboxMsg, deliverAt)
+}
+
+// FetchDue implements msg.ScheduledMessageStore.FetchDue
+func (s *ScheduledMessageStore) FetchDue(ctx context.Context, dueBy time.Time, limit int) ([]msg.Message, error) {
+	outboxMsgs, err := s.store.FetchDue(ctx, dueBy, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]msg.Message, 0, len(outboxMsgs))
+	for _, outboxMsg := range outboxMsgs {
+		message, err := outboxMsg.ToMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// Remove implements msg.ScheduledMessageStore.Remove
+func (s *ScheduledMessageStore) Remove(ctx context.Context, messageIDs []string) error {
+	return s.store.DeleteDelayed(ctx, messageIDs)
+}
@@ -89,6 +89,16 @@ func (b Backoff) Retry(ctx context.Context, fn func() error) error {
 	}
 }
 
+// Interval returns the interval to wait before the given retry attempt; The first retry attempt is 1
+func (b Backoff) Interval(attempt int) time.Duration {
+	interval := b.initialInterval
+	for i := 1; i < attempt; i++ {
+		interval = b.nextInterval(interval)
+	}
+
+	return interval
+}
+
 func (b Backoff) nextInterval(lastInterval time.Duration) time.Duration {
 	// Either there isn't any delay or there is not growth
 	if b.initialInterval == 0 || lastInterval == b.maxInterval {
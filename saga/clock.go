@@ -0,0 +1,29 @@
+package saga
+
+import (
+	"time"
+)
+
+// Clock is the source of time used by the Orchestrator for step deadlines
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, fn func()) Timer
+}
+
+// Timer is a timer created by Clock.AfterFunc
+type Timer interface {
+	Stop() bool
+}
+
+// SystemClock is a Clock that uses the system time
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, fn func()) Timer {
+	return time.AfterFunc(d, fn)
+}
@@ -3,9 +3,11 @@ package saga
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 	"github.com/stackus/edat/msg"
 )
 
@@ -17,6 +19,8 @@ type CommandDispatcher struct {
 	publisher msg.ReplyMessagePublisher
 	handlers  map[string]CommandHandlerFunc
 	logger    log.Logger
+	registry  metrics.Registry
+	metrics   msg.HandlerMetrics
 }
 
 var _ msg.MessageReceiver = (*CommandDispatcher)(nil)
@@ -27,12 +31,15 @@ func NewCommandDispatcher(publisher msg.ReplyMessagePublisher, options ...Comman
 		publisher: publisher,
 		handlers:  map[string]CommandHandlerFunc{},
 		logger:    log.DefaultLogger,
+		registry:  metrics.DefaultRegistry,
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
+	c.metrics = msg.NewHandlerMetrics(c.registry)
+
 	c.logger.Trace("saga.CommandDispatcher constructed")
 
 	return c
@@ -70,7 +77,7 @@ func (d *CommandDispatcher) ReceiveMessage(ctx context.Context, message msg.Mess
 
 	logger.Trace("saga command handler found")
 
-	command, err := core.DeserializeCommand(commandName, message.Payload())
+	command, err := core.DeserializeCommand(commandName, message.Payload(), message.Headers().Get(msg.MessageContentType))
 	if err != nil {
 		logger.Error("error decoding saga command message payload", log.Error(err))
 		return nil
@@ -84,9 +91,22 @@ func (d *CommandDispatcher) ReceiveMessage(ctx context.Context, message msg.Mess
 
 	correlationHeaders := d.correlationHeaders(message.Headers())
 
+	if msg.IsExpired(message) {
+		logger.Warn("saga command has expired", log.String("ExpiresAt", message.Headers().Get(msg.MessageExpiresAt)))
+		err = d.sendReplies(ctx, replyChannel, []msg.Reply{msg.WithExpired()}, correlationHeaders)
+		if err != nil {
+			logger.Error("error sending replies", log.Error(err))
+			return err
+		}
+		return nil
+	}
+
 	cmdMsg := commandMessage{sagaID, sagaName, command, correlationHeaders}
 
+	started := time.Now()
 	replies, err := handler(ctx, cmdMsg)
+
+	d.metrics.Observe("saga.CommandDispatcher", commandName, started, err)
 	if err != nil {
 		logger.Error("saga command handler returned an error", log.Error(err))
 		rerr := d.sendReplies(ctx, replyChannel, []msg.Reply{msg.WithFailure()}, correlationHeaders)
@@ -1,6 +1,8 @@
 package saga
 
 import (
+	"strconv"
+
 	"github.com/stackus/edat/msg"
 )
 
@@ -13,18 +15,55 @@ const (
 type LifecycleHook int
 
 // Definition lifecycle hooks
+//
+// The step hooks and SagaFailed are only received by definitions that implement HookDefinition. SagaStarting is called
+// before the new instance has been saved. The other hooks are called after the transition has been saved, and are not
+// called again when a message is processed again after a conflict with another process
 const (
 	SagaStarting LifecycleHook = iota
 	SagaCompleted
 	SagaCompensated
+	StepExecuting
+	ReplyReceived
+	StepFailed
+	CompensationStarted
+	SagaFailed
 )
 
+// String returns the name of the hook
+func (h LifecycleHook) String() string {
+	switch h {
+	case SagaStarting:
+		return "SagaStarting"
+	case SagaCompleted:
+		return "SagaCompleted"
+	case SagaCompensated:
+		return "SagaCompensated"
+	case StepExecuting:
+		return "StepExecuting"
+	case ReplyReceived:
+		return "ReplyReceived"
+	case StepFailed:
+		return "StepFailed"
+	case CompensationStarted:
+		return "CompensationStarted"
+	case SagaFailed:
+		return "SagaFailed"
+	default:
+		return "LifecycleHook(" + strconv.Itoa(int(h)) + ")"
+	}
+}
+
 // Saga message headers
 const (
-	MessageCommandSagaID   = msg.MessageCommandPrefix + "SAGA_ID"
-	MessageCommandSagaName = msg.MessageCommandPrefix + "SAGA_NAME"
-	MessageCommandResource = msg.MessageCommandPrefix + "RESOURCE"
+	MessageCommandSagaID     = msg.MessageCommandPrefix + "SAGA_ID"
+	MessageCommandSagaName   = msg.MessageCommandPrefix + "SAGA_NAME"
+	MessageCommandSagaStepID = msg.MessageCommandPrefix + "SAGA_STEP_ID"
+	MessageCommandSagaBranch = msg.MessageCommandPrefix + "SAGA_BRANCH"
+	MessageCommandResource   = msg.MessageCommandPrefix + "RESOURCE"
 
-	MessageReplySagaID   = msg.MessageReplyPrefix + "SAGA_ID"
-	MessageReplySagaName = msg.MessageReplyPrefix + "SAGA_NAME"
+	MessageReplySagaID     = msg.MessageReplyPrefix + "SAGA_ID"
+	MessageReplySagaName   = msg.MessageReplyPrefix + "SAGA_NAME"
+	MessageReplySagaStepID = msg.MessageReplyPrefix + "SAGA_STEP_ID"
+	MessageReplySagaBranch = msg.MessageReplyPrefix + "SAGA_BRANCH"
 )
@@ -1,5 +1,9 @@
 package saga
 
+import (
+	"context"
+)
+
 // Definition interface
 type Definition interface {
 	SagaName() string
@@ -7,3 +11,26 @@ type Definition interface {
 	Steps() []Step
 	OnHook(hook LifecycleHook, instance *Instance)
 }
+
+// HookDefinition is implemented by definitions that receive every lifecycle hook with a context and step metadata
+//
+// OnLifecycleHook is called in place of Definition.OnHook. Hook errors are logged, and are returned when the
+// Orchestrator is constructed with WithOrchestratorAbortOnHookError
+type HookDefinition interface {
+	Definition
+	OnLifecycleHook(ctx context.Context, info HookInfo) error
+}
+
+// HookInfo is the saga instance and step metadata passed to lifecycle hooks
+//
+// ReplyName and Outcome are set for the ReplyReceived and StepFailed hooks of replies. Err is set for the StepFailed
+// hooks of local steps and for the SagaFailed hook
+type HookInfo struct {
+	Hook         LifecycleHook
+	Instance     *Instance
+	Step         int
+	Compensating bool
+	ReplyName    string
+	Outcome      string
+	Err          error
+}
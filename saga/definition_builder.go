@@ -0,0 +1,270 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Definition validation errors
+var (
+	ErrMissingSagaName         = errors.New("saga name is required")
+	ErrMissingReplyChannel     = errors.New("reply channel is required")
+	ErrMissingSteps            = errors.New("at least one step is required")
+	ErrStepWithoutActions      = errors.New("step has no action or compensation")
+	ErrUnreachableCompensation = errors.New("compensation on the last step is never run")
+	ErrDuplicateReplyHandler   = errors.New("duplicate reply handler")
+	ErrMissingCorrelation      = errors.New("event step has no correlation functions")
+	ErrBranchRetryPolicy       = errors.New("retry policies are not supported by parallel step branches")
+)
+
+// DefinitionErrors is returned by DefinitionBuilder.Build with every problem found in the definition
+type DefinitionErrors []error
+
+// Error implements error.Error
+func (e DefinitionErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return "invalid saga definition: " + strings.Join(messages, "; ")
+}
+
+// Is returns whether or not any of the definition errors match the target
+func (e DefinitionErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DefinitionBuilder builds a validated Definition
+//
+//  definition, err := saga.NewDefinition("orderSaga", "orderSaga.reply").
+//  	Remote(saga.NewRemoteStep().Action(createOrder).Compensation(rejectOrder)).
+//  	Remote(saga.NewRemoteStep().Action(approveOrder)).
+//  	OnCompleted(func(instance *saga.Instance) { ... }).
+//  	Build()
+type DefinitionBuilder struct {
+	sagaName     string
+	replyChannel string
+	steps        []Step
+	hooks        map[LifecycleHook][]func(context.Context, HookInfo) error
+}
+
+type definition struct {
+	sagaName     string
+	replyChannel string
+	steps        []Step
+	hooks        map[LifecycleHook][]func(context.Context, HookInfo) error
+}
+
+var _ HookDefinition = (*definition)(nil)
+
+// NewDefinition constructor for DefinitionBuilder
+func NewDefinition(sagaName, replyChannel string) *DefinitionBuilder {
+	return &DefinitionBuilder{
+		sagaName:     sagaName,
+		replyChannel: replyChannel,
+		hooks:        map[LifecycleHook][]func(context.Context, HookInfo) error{},
+	}
+}
+
+// Local adds a LocalStep to the definition
+func (b *DefinitionBuilder) Local(step LocalStep) *DefinitionBuilder {
+	b.steps = append(b.steps, step)
+	return b
+}
+
+// Remote adds a RemoteStep to the definition
+func (b *DefinitionBuilder) Remote(step RemoteStep) *DefinitionBuilder {
+	b.steps = append(b.steps, step)
+	return b
+}
+
+// Parallel adds a ParallelStep with the branches to the definition
+//
+// Branches with retry policies are reported by Build with ErrBranchRetryPolicy
+func (b *DefinitionBuilder) Parallel(branches ...RemoteStep) *DefinitionBuilder {
+	b.steps = append(b.steps, NewParallelStep(branches...))
+	return b
+}
+
+// Event adds an EventStep to the definition
+func (b *DefinitionBuilder) Event(step EventStep) *DefinitionBuilder {
+	b.steps = append(b.steps, step)
+	return b
+}
+
+// OnHook adds a hook that is called with a context and the step metadata for the lifecycle hook
+//
+// Multiple hooks may be added for a lifecycle hook and will be run in the order they were added. The first error
+// returned stops the remaining hooks from being run
+func (b *DefinitionBuilder) OnHook(hook LifecycleHook, fn func(context.Context, HookInfo) error) *DefinitionBuilder {
+	b.hooks[hook] = append(b.hooks[hook], fn)
+	return b
+}
+
+// OnStarting adds a hook that is called before the first step of a new saga is run
+func (b *DefinitionBuilder) OnStarting(hook func(*Instance)) *DefinitionBuilder {
+	return b.OnHook(SagaStarting, instanceHook(hook))
+}
+
+// OnCompleted adds a hook that is called when the saga has run all of its steps
+func (b *DefinitionBuilder) OnCompleted(hook func(*Instance)) *DefinitionBuilder {
+	return b.OnHook(SagaCompleted, instanceHook(hook))
+}
+
+// OnCompensated adds a hook that is called when the saga has finished compensating
+func (b *DefinitionBuilder) OnCompensated(hook func(*Instance)) *DefinitionBuilder {
+	return b.OnHook(SagaCompensated, instanceHook(hook))
+}
+
+// OnStepExecuting adds a hook that is called once each step has been started and saved, and its commands sent
+func (b *DefinitionBuilder) OnStepExecuting(hook func(context.Context, HookInfo) error) *DefinitionBuilder {
+	return b.OnHook(StepExecuting, hook)
+}
+
+// OnReplyReceived adds a hook that is called once each reply, including timeouts, has been handled and saved
+func (b *DefinitionBuilder) OnReplyReceived(hook func(context.Context, HookInfo) error) *DefinitionBuilder {
+	return b.OnHook(ReplyReceived, hook)
+}
+
+// OnStepFailed adds a hook that is called when a step that is not compensating fails
+func (b *DefinitionBuilder) OnStepFailed(hook func(context.Context, HookInfo) error) *DefinitionBuilder {
+	return b.OnHook(StepFailed, hook)
+}
+
+// OnCompensationStarted adds a hook that is called when the saga begins compensating
+func (b *DefinitionBuilder) OnCompensationStarted(hook func(context.Context, HookInfo) error) *DefinitionBuilder {
+	return b.OnHook(CompensationStarted, hook)
+}
+
+// OnSagaFailed adds a hook that is called when the saga cannot continue, such as when a compensation fails
+func (b *DefinitionBuilder) OnSagaFailed(hook func(context.Context, HookInfo) error) *DefinitionBuilder {
+	return b.OnHook(SagaFailed, hook)
+}
+
+// Build validates the steps and returns the Definition
+//
+// All problems are returned together as DefinitionErrors
+func (b *DefinitionBuilder) Build() (Definition, error) {
+	var errs DefinitionErrors
+
+	if b.sagaName == "" {
+		errs = append(errs, ErrMissingSagaName)
+	}
+
+	if b.replyChannel == "" {
+		errs = append(errs, ErrMissingReplyChannel)
+	}
+
+	if len(b.steps) == 0 {
+		errs = append(errs, ErrMissingSteps)
+	}
+
+	for i, step := range b.steps {
+		last := i == len(b.steps)-1
+
+		switch s := step.(type) {
+		case LocalStep:
+			if s.actions[notCompensating] == nil && s.actions[isCompensating] == nil {
+				errs = append(errs, fmt.Errorf("step %d: %w", i, ErrStepWithoutActions))
+			}
+			if last && s.actions[isCompensating] != nil {
+				errs = append(errs, fmt.Errorf("step %d: %w", i, ErrUnreachableCompensation))
+			}
+		case RemoteStep:
+			errs = append(errs, validateRemoteStep(fmt.Sprintf("step %d", i), s)...)
+			if last && s.actionHandlers[isCompensating] != nil {
+				errs = append(errs, fmt.Errorf("step %d: %w", i, ErrUnreachableCompensation))
+			}
+		case EventStep:
+			if s.sagaKey == nil || s.eventKey == nil {
+				errs = append(errs, fmt.Errorf("step %d: %w", i, ErrMissingCorrelation))
+			}
+		case ParallelStep:
+			// branch compensations of the last step are run when any other branch fails
+			if len(s.branches) == 0 {
+				errs = append(errs, fmt.Errorf("step %d: %w", i, ErrStepWithoutActions))
+			}
+			for j, branch := range s.branches {
+				errs = append(errs, validateRemoteStep(fmt.Sprintf("step %d branch %d", i, j), branch)...)
+			}
+			errs = append(errs, s.branchRetryPolicies(fmt.Sprintf("step %d", i))...)
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	hooks := make(map[LifecycleHook][]func(context.Context, HookInfo) error, len(b.hooks))
+	for hook, fns := range b.hooks {
+		hooks[hook] = append([]func(context.Context, HookInfo) error{}, fns...)
+	}
+
+	return &definition{
+		sagaName:     b.sagaName,
+		replyChannel: b.replyChannel,
+		steps:        append([]Step{}, b.steps...),
+		hooks:        hooks,
+	}, nil
+}
+
+func validateRemoteStep(name string, step RemoteStep) []error {
+	var errs []error
+
+	if step.actionHandlers[notCompensating] == nil && step.actionHandlers[isCompensating] == nil {
+		errs = append(errs, fmt.Errorf("%s: %w", name, ErrStepWithoutActions))
+	}
+
+	for _, replyName := range step.duplicates {
+		errs = append(errs, fmt.Errorf("%s: %w for `%s`", name, ErrDuplicateReplyHandler, replyName))
+	}
+
+	return errs
+}
+
+func (d *definition) SagaName() string {
+	return d.sagaName
+}
+
+func (d *definition) ReplyChannel() string {
+	return d.replyChannel
+}
+
+func (d *definition) Steps() []Step {
+	return d.steps
+}
+
+func (d *definition) OnHook(hook LifecycleHook, instance *Instance) {
+	_ = d.OnLifecycleHook(context.Background(), HookInfo{
+		Hook:         hook,
+		Instance:     instance,
+		Step:         instance.currentStep,
+		Compensating: instance.compensating,
+	})
+}
+
+func (d *definition) OnLifecycleHook(ctx context.Context, info HookInfo) error {
+	for _, fn := range d.hooks[info.Hook] {
+		if err := fn(ctx, info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func instanceHook(hook func(*Instance)) func(context.Context, HookInfo) error {
+	return func(_ context.Context, info HookInfo) error {
+		hook(info.Instance)
+		return nil
+	}
+}
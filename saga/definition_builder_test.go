@@ -0,0 +1,189 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+)
+
+func TestDefinitionBuilder_Build(t *testing.T) {
+	action := func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }
+	compensation := func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }
+	local := func(context.Context, core.SagaData) error { return nil }
+	handler := func(context.Context, core.SagaData, core.Reply) error { return nil }
+
+	tests := map[string]struct {
+		builder  *saga.DefinitionBuilder
+		wantErrs []error
+	}{
+		"Valid": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Local(saga.NewLocalStep(local).Compensation(local)).
+				Remote(saga.NewRemoteStep().Action(action).Compensation(compensation)).
+				Remote(saga.NewRemoteStep().Action(action).HandleActionReply(msg.Failure{}, handler)),
+		},
+		"CompensationOnlyStep": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().Compensation(compensation)).
+				Remote(saga.NewRemoteStep().Action(action)),
+		},
+		"ParallelLastStep": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Parallel(
+					saga.NewRemoteStep().Action(action).Compensation(compensation),
+					saga.NewRemoteStep().Action(action).Compensation(compensation),
+				),
+		},
+		"MissingNames": {
+			builder:  saga.NewDefinition("", "").Remote(saga.NewRemoteStep().Action(action)),
+			wantErrs: []error{saga.ErrMissingSagaName, saga.ErrMissingReplyChannel},
+		},
+		"MissingSteps": {
+			builder:  saga.NewDefinition("orderSaga", "orderSaga.reply"),
+			wantErrs: []error{saga.ErrMissingSteps},
+		},
+		"LocalWithoutActions": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Local(saga.NewLocalStep(nil)).
+				Remote(saga.NewRemoteStep().Action(action)),
+			wantErrs: []error{saga.ErrStepWithoutActions},
+		},
+		"RemoteWithoutActions": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep()),
+			wantErrs: []error{saga.ErrStepWithoutActions},
+		},
+		"ParallelWithoutBranches": {
+			builder:  saga.NewDefinition("orderSaga", "orderSaga.reply").Parallel(),
+			wantErrs: []error{saga.ErrStepWithoutActions},
+		},
+		"BranchWithoutActions": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Parallel(saga.NewRemoteStep().Action(action), saga.NewRemoteStep()),
+			wantErrs: []error{saga.ErrStepWithoutActions},
+		},
+		"BranchRetryPolicy": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Parallel(
+					saga.NewRemoteStep().Action(action),
+					saga.NewRemoteStep().Action(action, saga.WithRemoteStepRetry(saga.NewRetryPolicy(3))),
+				),
+			wantErrs: []error{saga.ErrBranchRetryPolicy},
+		},
+		"EventWithoutCorrelation": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Event(saga.NewEventStep("payments", paymentCaptured{})),
+			wantErrs: []error{saga.ErrMissingCorrelation},
+		},
+		"LastLocalCompensation": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Local(saga.NewLocalStep(local).Compensation(local)),
+			wantErrs: []error{saga.ErrUnreachableCompensation},
+		},
+		"LastRemoteCompensation": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().Action(action)).
+				Remote(saga.NewRemoteStep().Action(action).Compensation(compensation)),
+			wantErrs: []error{saga.ErrUnreachableCompensation},
+		},
+		"DuplicateActionReplyHandler": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().Action(action).
+					HandleActionReply(msg.Failure{}, handler).
+					HandleActionReply(msg.Failure{}, handler)),
+			wantErrs: []error{saga.ErrDuplicateReplyHandler},
+		},
+		"DuplicateCompensationReplyHandler": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().Action(action).Compensation(compensation).
+					HandleCompensationReply(msg.Success{}, handler).
+					HandleCompensationReply(msg.Success{}, handler)).
+				Remote(saga.NewRemoteStep().Action(action)),
+			wantErrs: []error{saga.ErrDuplicateReplyHandler},
+		},
+		"SameReplyBothDirections": {
+			builder: saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().Action(action).Compensation(compensation).
+					HandleActionReply(msg.Success{}, handler).
+					HandleCompensationReply(msg.Success{}, handler)).
+				Remote(saga.NewRemoteStep().Action(action)),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			definition, err := tt.builder.Build()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Build() error = %v", err)
+				}
+				if definition == nil {
+					t.Errorf("Build() definition = nil")
+				}
+				return
+			}
+
+			var errs saga.DefinitionErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Build() error = %v, want DefinitionErrors", err)
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Errorf("Build() error = %v, want %d errors", err, len(tt.wantErrs))
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Build() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDefinitionBuilder_Hooks(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	var hooks []string
+	definition, err := saga.NewDefinition("orderSaga", "orderSaga.reply").
+		Local(saga.NewLocalStep(func(context.Context, core.SagaData) error { return nil })).
+		Remote(saga.NewRemoteStep().
+			Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} })).
+		OnStarting(func(*saga.Instance) { hooks = append(hooks, "starting") }).
+		OnCompleted(func(*saga.Instance) { hooks = append(hooks, "completed") }).
+		OnCompleted(func(*saga.Instance) { hooks = append(hooks, "completed again") }).
+		OnCompensated(func(*saga.Instance) { hooks = append(hooks, "compensated") }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	orchestrator := saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), publisher)
+
+	ctx := context.Background()
+	if _, err = orchestrator.Start(ctx, &orderData{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	sent := publisher.sent()
+	if len(sent) != 1 || sent[0].command.CommandName() != "saga_test.approveOrder" {
+		t.Fatalf("commands sent = %v, want the approveOrder command", sent)
+	}
+
+	_ = orchestrator.ReceiveMessage(ctx, replyTo(sent[0].message, msg.WithSuccess()))
+
+	want := []string{"starting", "completed", "completed again"}
+	if len(hooks) != len(want) {
+		t.Fatalf("hooks = %v, want %v", hooks, want)
+	}
+	for i := range want {
+		if hooks[i] != want[i] {
+			t.Errorf("hooks = %v, want %v", hooks, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/msg"
+)
+
+// ErrMissingCorrelationKey is the failure of an EventStep when the saga data has no correlation key
+var ErrMissingCorrelationKey = errors.New("event step correlation key is empty")
+
+// EventStep is used to wait for a domain event before the saga continues
+//
+// Events are correlated with the waiting saga instance by a key. The key of the instance is taken from the saga data
+// when the step begins and the key of each event is taken from the event
+//  saga.NewEventStep("payments", PaymentCaptured{}).
+//  	Correlate(
+//  		func(ctx context.Context, data core.SagaData) string { return data.(*OrderData).OrderID },
+//  		func(ctx context.Context, event msg.Event) string { return event.Event().(*PaymentCaptured).OrderID },
+//  	).
+//  	Timeout(24 * time.Hour)
+//
+// The step fails with a StepTimedOut reply when the event has not been received in time. The Orchestrator must be
+// subscribed to the event channel, and the InstanceStore must implement InstanceCorrelator
+type EventStep struct {
+	channel   string
+	eventName string
+	sagaKey   func(context.Context, core.SagaData) string
+	eventKey  func(context.Context, msg.Event) string
+	handler   func(context.Context, core.SagaData, msg.Event) error
+	timeout   time.Duration
+}
+
+var _ Step = (*EventStep)(nil)
+
+// NewEventStep constructor for EventStep
+func NewEventStep(channel string, event core.Event) EventStep {
+	return EventStep{
+		channel:   channel,
+		eventName: event.EventName(),
+	}
+}
+
+// Correlate sets the functions that return the correlation keys of the saga data and of the events
+func (s EventStep) Correlate(sagaKey func(context.Context, core.SagaData) string, eventKey func(context.Context, msg.Event) string) EventStep {
+	s.sagaKey = sagaKey
+	s.eventKey = eventKey
+	return s
+}
+
+// Handle sets a handler that is called with the saga data and the event before the saga continues
+func (s EventStep) Handle(handler func(context.Context, core.SagaData, msg.Event) error) EventStep {
+	s.handler = handler
+	return s
+}
+
+// Timeout sets how long the step may wait for the event
+func (s EventStep) Timeout(timeout time.Duration) EventStep {
+	s.timeout = timeout
+	return s
+}
+
+// Channel returns the channel the event is published to
+func (s EventStep) Channel() string {
+	return s.channel
+}
+
+// EventName returns the name of the event the step waits for
+func (s EventStep) EventName() string {
+	return s.eventName
+}
+
+// there is nothing to compensate for an event that has been received
+func (s EventStep) hasInvocableAction(_ context.Context, _ core.SagaData, compensating bool) bool {
+	return !compensating
+}
+
+func (s EventStep) getReplyHandler(string, bool) func(context.Context, core.SagaData, core.Reply) error {
+	return nil
+}
+
+func (s EventStep) getRetryPolicy(bool) *RetryPolicy {
+	return nil
+}
+
+func (s EventStep) execute(ctx context.Context, sagaData core.SagaData, _ bool) func(results *stepResults) {
+	key := s.sagaKey(ctx, sagaData)
+
+	return func(results *stepResults) {
+		if key == "" {
+			results.failure = ErrMissingCorrelationKey
+			return
+		}
+
+		results.correlationKey = key
+		results.timeout = s.timeout
+	}
+}
@@ -0,0 +1,280 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+	"github.com/stackus/edat/saga/sagatest"
+)
+
+type paymentCaptured struct{ OrderID string }
+
+func (paymentCaptured) EventName() string { return "saga_test.paymentCaptured" }
+
+type paymentData struct {
+	OrderID  string
+	Captured bool
+}
+
+func (paymentData) SagaDataName() string { return "saga_test.paymentData" }
+
+type recordingSubscriber struct {
+	channels []string
+}
+
+func (s *recordingSubscriber) Subscribe(channel string, _ msg.MessageReceiver) {
+	s.channels = append(s.channels, channel)
+}
+
+func eventMessage(t *testing.T, event core.Event) msg.Message {
+	payload, err := core.SerializeEvent(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg.NewMessage(payload, msg.WithHeaders(map[string]string{
+		msg.MessageEventName: event.EventName(),
+	}))
+}
+
+func paymentDefinition(timeout time.Duration) *testDefinition {
+	return &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+			saga.NewEventStep("payments", paymentCaptured{}).
+				Correlate(
+					func(_ context.Context, data core.SagaData) string { return data.(*paymentData).OrderID },
+					func(_ context.Context, event msg.Event) string { return event.Event().(*paymentCaptured).OrderID },
+				).
+				Handle(func(_ context.Context, data core.SagaData, _ msg.Event) error {
+					data.(*paymentData).Captured = true
+					return nil
+				}).
+				Timeout(timeout),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+}
+
+func TestOrchestrator_EventStep(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	core.RegisterEvents(paymentCaptured{})
+	msg.RegisterTypes()
+
+	tests := map[string]struct {
+		events    []core.Event
+		advance   time.Duration
+		wantSent  []string
+		wantState saga.InstanceState
+		wantKey   string
+	}{
+		"EventReceived": {
+			events:    []core.Event{paymentCaptured{OrderID: "order-1"}},
+			wantSent:  []string{"saga_test.approveOrder"},
+			wantState: saga.InstanceRunning,
+		},
+		"OtherInstanceEvent": {
+			events:    []core.Event{paymentCaptured{OrderID: "order-2"}},
+			wantState: saga.InstanceRunning,
+			wantKey:   "order-1",
+		},
+		"DuplicateEvent": {
+			events:    []core.Event{paymentCaptured{OrderID: "order-1"}, paymentCaptured{OrderID: "order-1"}},
+			wantSent:  []string{"saga_test.approveOrder"},
+			wantState: saga.InstanceRunning,
+		},
+		"TimedOut": {
+			advance:   time.Hour,
+			wantSent:  []string{"saga_test.releaseCredit"},
+			wantState: saga.InstanceCompensating,
+		},
+		"EventAfterTimeout": {
+			advance:   time.Hour,
+			events:    []core.Event{paymentCaptured{OrderID: "order-1"}},
+			wantSent:  []string{"saga_test.releaseCredit"},
+			wantState: saga.InstanceCompensating,
+		},
+		"EventBeforeTimeout": {
+			advance:   30 * time.Minute,
+			events:    []core.Event{paymentCaptured{OrderID: "order-1"}},
+			wantSent:  []string{"saga_test.approveOrder"},
+			wantState: saga.InstanceRunning,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+			publisher := &recordingPublisher{}
+			store := inmem.NewSagaInstanceStore()
+			definition := paymentDefinition(45 * time.Minute)
+
+			orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+			ctx := context.Background()
+			data := &paymentData{OrderID: "order-1"}
+			instance, err := orchestrator.Start(ctx, data)
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+			if err != nil {
+				t.Fatalf("ReceiveMessage() error = %v", err)
+			}
+
+			found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+			if found.CorrelationKey() != "order-1" || found.Deadline().IsZero() {
+				t.Fatalf("waiting instance key = %q, deadline = %v", found.CorrelationKey(), found.Deadline())
+			}
+
+			clock.Advance(tt.advance)
+
+			for _, event := range tt.events {
+				err = orchestrator.ReceiveMessage(ctx, eventMessage(t, event))
+				if err != nil {
+					t.Fatalf("ReceiveMessage() error = %v", err)
+				}
+			}
+
+			var sent []string
+			for _, command := range publisher.sent()[1:] {
+				sent = append(sent, command.command.CommandName())
+			}
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("commands sent = %v, want %v", sent, tt.wantSent)
+			}
+
+			found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+			if found.State() != tt.wantState {
+				t.Errorf("State() = %v, want %v", found.State(), tt.wantState)
+			}
+			if found.CorrelationKey() != tt.wantKey {
+				t.Errorf("CorrelationKey() = %q, want %q", found.CorrelationKey(), tt.wantKey)
+			}
+			wantCaptured := reflect.DeepEqual(tt.wantSent, []string{"saga_test.approveOrder"})
+			if captured := found.SagaData().(*paymentData).Captured; captured != wantCaptured {
+				t.Errorf("Captured = %v, want %v", captured, wantCaptured)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_EventStepSharedKey(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	core.RegisterEvents(paymentCaptured{})
+	msg.RegisterTypes()
+
+	eventStep := saga.NewEventStep("payments", paymentCaptured{}).
+		Correlate(
+			func(_ context.Context, data core.SagaData) string { return data.(*paymentData).OrderID },
+			func(_ context.Context, event msg.Event) string { return event.Event().(*paymentCaptured).OrderID },
+		)
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	definition := &testDefinition{
+		steps: []saga.Step{
+			eventStep,
+			eventStep,
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher)
+
+	ctx := context.Background()
+
+	// both instances wait with the same key, one on each of the event steps
+	ahead, err := orchestrator.Start(ctx, &paymentData{OrderID: "order-1"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err = orchestrator.ReceiveMessage(ctx, eventMessage(t, paymentCaptured{OrderID: "order-1"})); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	behind, err := orchestrator.Start(ctx, &paymentData{OrderID: "order-1"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err = orchestrator.ReceiveMessage(ctx, eventMessage(t, paymentCaptured{OrderID: "order-1"})); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	wantSteps := map[string]int{ahead.SagaID(): 2, behind.SagaID(): 1}
+	for sagaID, want := range wantSteps {
+		found, _ := store.Find(ctx, definition.SagaName(), sagaID)
+		if found.CurrentStep() != want {
+			t.Errorf("CurrentStep() = %d, want %d", found.CurrentStep(), want)
+		}
+	}
+	if got := len(publisher.sent()); got != 1 {
+		t.Errorf("commands sent = %d, want 1", got)
+	}
+}
+
+func TestNewValidatedOrchestrator(t *testing.T) {
+	definition := paymentDefinition(0)
+
+	_, err := saga.NewValidatedOrchestrator(definition, struct{ saga.InstanceStore }{inmem.NewSagaInstanceStore()}, &recordingPublisher{})
+	if !errors.Is(err, saga.ErrInstanceStoreNotCorrelator) {
+		t.Errorf("NewValidatedOrchestrator() error = %v, want %v", err, saga.ErrInstanceStoreNotCorrelator)
+	}
+
+	orchestrator, err := saga.NewValidatedOrchestrator(definition, inmem.NewSagaInstanceStore(), &recordingPublisher{})
+	if err != nil || orchestrator == nil {
+		t.Errorf("NewValidatedOrchestrator() error = %v", err)
+	}
+}
+
+func TestOrchestrator_EventStepMissingKey(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	definition := paymentDefinition(0)
+	orchestrator := saga.NewOrchestrator(definition, store, publisher)
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &paymentData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	_ = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceCompensating {
+		t.Errorf("State() = %v, want %v", found.State(), saga.InstanceCompensating)
+	}
+	if sent := publisher.sent(); sent[len(sent)-1].command.CommandName() != "saga_test.releaseCredit" {
+		t.Errorf("last command = %v, want saga_test.releaseCredit", sent[len(sent)-1].command.CommandName())
+	}
+}
+
+func TestOrchestrator_Subscribe(t *testing.T) {
+	definition := paymentDefinition(0)
+	definition.steps = append(definition.steps,
+		saga.NewEventStep("payments", paymentCaptured{}),
+		saga.NewEventStep("shipping", paymentCaptured{}),
+	)
+
+	subscriber := &recordingSubscriber{}
+	saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), &recordingPublisher{}).Subscribe(subscriber)
+
+	want := []string{"saga_test.OrderSaga.reply", "payments", "shipping"}
+	if !reflect.DeepEqual(subscriber.channels, want) {
+		t.Errorf("subscribed channels = %v, want %v", subscriber.channels, want)
+	}
+}
@@ -0,0 +1,46 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// HistoryRecordType is the kind of transition recorded in the history of a saga instance
+type HistoryRecordType string
+
+// History record types
+const (
+	HistoryStepStarted         HistoryRecordType = "step_started"
+	HistoryCommandSent         HistoryRecordType = "command_sent"
+	HistoryReplyReceived       HistoryRecordType = "reply_received"
+	HistoryEventReceived       HistoryRecordType = "event_received"
+	HistoryCompensationStarted HistoryRecordType = "compensation_started"
+	HistoryHookFired           HistoryRecordType = "hook_fired"
+	HistorySagaFailed          HistoryRecordType = "saga_failed"
+	HistoryIntervention        HistoryRecordType = "intervention"
+)
+
+// HistoryRecord is a single transition of a saga instance
+//
+// Name is the command, reply, event, hook, or intervention name. MessageID and Outcome are set for the commands
+// sent and the replies received. Detail holds additional information such as the branch of a ParallelStep
+type HistoryRecord struct {
+	SagaName     string
+	SagaID       string
+	Type         HistoryRecordType
+	Step         int
+	Compensating bool
+	Name         string
+	MessageID    string
+	Outcome      string
+	Detail       string
+	At           time.Time
+}
+
+// HistoryStore is used to record and retrieve the transitions of saga instances
+//
+// History returns the records in the order they were recorded
+type HistoryStore interface {
+	Record(ctx context.Context, records ...HistoryRecord) error
+	History(ctx context.Context, sagaName, sagaID string) ([]HistoryRecord, error)
+}
@@ -0,0 +1,144 @@
+package saga_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+	"github.com/stackus/edat/saga/sagatest"
+)
+
+func TestOrchestrator_History(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher := &recordingPublisher{}
+	history := inmem.NewSagaHistoryStore()
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), publisher,
+		saga.WithOrchestratorClock(clock),
+		saga.WithOrchestratorHistory(history),
+	)
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var replies []msg.Message
+	for _, reply := range []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithSuccess()} {
+		clock.Advance(time.Second)
+		sent := publisher.sent()
+		message := replyTo(sent[len(sent)-1].message, reply)
+		replies = append(replies, message)
+		if err = orchestrator.ReceiveMessage(ctx, message); err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+	}
+
+	sent := publisher.sent()
+	if len(sent) != 3 {
+		t.Fatalf("commands sent = %d, want 3", len(sent))
+	}
+
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := func(at int, recordType saga.HistoryRecordType, step int, compensating bool, name, messageID, outcome string) saga.HistoryRecord {
+		return saga.HistoryRecord{
+			SagaName:     definition.SagaName(),
+			SagaID:       instance.SagaID(),
+			Type:         recordType,
+			Step:         step,
+			Compensating: compensating,
+			Name:         name,
+			MessageID:    messageID,
+			Outcome:      outcome,
+			At:           start.Add(time.Duration(at) * time.Second),
+		}
+	}
+
+	want := []saga.HistoryRecord{
+		record(0, saga.HistoryHookFired, 0, false, "SagaStarting", "", ""),
+		record(0, saga.HistoryStepStarted, 0, false, "", "", ""),
+		record(0, saga.HistoryCommandSent, 0, false, "saga_test.reserveCredit", sent[0].message.ID(), ""),
+		record(1, saga.HistoryReplyReceived, 0, false, "edat.msg.Success", replies[0].ID(), msg.ReplyOutcomeSuccess),
+		record(1, saga.HistoryStepStarted, 1, false, "", "", ""),
+		record(1, saga.HistoryCommandSent, 1, false, "saga_test.approveOrder", sent[1].message.ID(), ""),
+		record(2, saga.HistoryReplyReceived, 1, false, "edat.msg.Failure", replies[1].ID(), msg.ReplyOutcomeFailure),
+		record(2, saga.HistoryCompensationStarted, 0, true, "", "", ""),
+		record(2, saga.HistoryStepStarted, 0, true, "", "", ""),
+		record(2, saga.HistoryCommandSent, 0, true, "saga_test.releaseCredit", sent[2].message.ID(), ""),
+		record(3, saga.HistoryReplyReceived, 0, true, "edat.msg.Success", replies[2].ID(), msg.ReplyOutcomeSuccess),
+		record(3, saga.HistoryHookFired, 0, true, "SagaCompensated", "", ""),
+	}
+
+	got, err := history.History(ctx, definition.SagaName(), instance.SagaID())
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("History() = %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("History()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOrchestrator_HistoryRejected(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	publisher := &recordingPublisher{}
+	history := inmem.NewSagaHistoryStore()
+	definition := paymentDefinition(0)
+	orchestrator := saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), publisher, saga.WithOrchestratorHistory(history))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &paymentData{OrderID: "order-1"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	reply := replyTo(publisher.sent()[0].message, msg.WithSuccess())
+	if err = orchestrator.ReceiveMessage(ctx, reply); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	// the redelivered reply is for a previous step, and the event step has nothing to retry
+	if err = orchestrator.ReceiveMessage(ctx, reply); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err = orchestrator.RetryStep(ctx, instance.SagaID(), "operator", "stuck"); err == nil {
+		t.Fatalf("RetryStep() error = nil")
+	}
+
+	got, err := history.History(ctx, definition.SagaName(), instance.SagaID())
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	counts := map[saga.HistoryRecordType]int{}
+	for _, record := range got {
+		counts[record.Type]++
+	}
+	if counts[saga.HistoryReplyReceived] != 1 || counts[saga.HistoryIntervention] != 0 {
+		t.Errorf("History() = %+v, want one reply and no interventions", got)
+	}
+}
@@ -0,0 +1,312 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+)
+
+func TestOrchestrator_LifecycleHooks(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	localErr := errors.New("local step failed")
+
+	remote := func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }
+	compensation := func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }
+	approve := func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }
+
+	tests := map[string]struct {
+		steps   []saga.Step
+		replies []msg.Reply
+		want    []string
+	}{
+		"Completed": {
+			steps: []saga.Step{
+				saga.NewRemoteStep().Action(remote).Compensation(compensation),
+				saga.NewRemoteStep().Action(approve),
+			},
+			replies: []msg.Reply{msg.WithSuccess(), msg.WithSuccess()},
+			want: []string{
+				"SagaStarting 0 false",
+				"StepExecuting 0 false",
+				"ReplyReceived 0 false edat.msg.Success SUCCESS",
+				"StepExecuting 1 false",
+				"ReplyReceived 1 false edat.msg.Success SUCCESS",
+				"SagaCompleted 1 false",
+			},
+		},
+		"Compensated": {
+			steps: []saga.Step{
+				saga.NewRemoteStep().Action(remote).Compensation(compensation),
+				saga.NewRemoteStep().Action(approve),
+			},
+			replies: []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithSuccess()},
+			want: []string{
+				"SagaStarting 0 false",
+				"StepExecuting 0 false",
+				"ReplyReceived 0 false edat.msg.Success SUCCESS",
+				"StepExecuting 1 false",
+				"ReplyReceived 1 false edat.msg.Failure FAILURE",
+				"StepFailed 1 false edat.msg.Failure FAILURE",
+				"CompensationStarted 0 true",
+				"StepExecuting 0 true",
+				"ReplyReceived 0 true edat.msg.Success SUCCESS",
+				"SagaCompensated 0 true",
+			},
+		},
+		"LocalStepFailed": {
+			steps: []saga.Step{
+				saga.NewRemoteStep().Action(remote).Compensation(compensation),
+				saga.NewLocalStep(func(context.Context, core.SagaData) error { return localErr }),
+			},
+			replies: []msg.Reply{msg.WithSuccess()},
+			want: []string{
+				"SagaStarting 0 false",
+				"StepExecuting 0 false",
+				"ReplyReceived 0 false edat.msg.Success SUCCESS",
+				"StepExecuting 1 false",
+				"StepFailed 1 false   local step failed",
+				"CompensationStarted 0 true",
+				"StepExecuting 0 true",
+			},
+		},
+		"SagaFailed": {
+			steps: []saga.Step{
+				saga.NewRemoteStep().Action(remote).Compensation(compensation),
+				saga.NewRemoteStep().Action(approve),
+			},
+			replies: []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithFailure()},
+			want: []string{
+				"SagaStarting 0 false",
+				"StepExecuting 0 false",
+				"ReplyReceived 0 false edat.msg.Success SUCCESS",
+				"StepExecuting 1 false",
+				"ReplyReceived 1 false edat.msg.Failure FAILURE",
+				"StepFailed 1 false edat.msg.Failure FAILURE",
+				"CompensationStarted 0 true",
+				"StepExecuting 0 true",
+				"ReplyReceived 0 true edat.msg.Failure FAILURE",
+				"SagaFailed 0 true   " + saga.ErrCompensationFailed.Error(),
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			record := func(_ context.Context, info saga.HookInfo) error {
+				hook := fmt.Sprintf("%s %d %v", info.Hook, info.Step, info.Compensating)
+				if info.ReplyName != "" || info.Err != nil {
+					hook += fmt.Sprintf(" %s %s", info.ReplyName, info.Outcome)
+				}
+				if info.Err != nil {
+					hook += " " + info.Err.Error()
+				}
+				got = append(got, hook)
+				return nil
+			}
+
+			builder := saga.NewDefinition("orderSaga", "orderSaga.reply")
+			for _, step := range tt.steps {
+				switch s := step.(type) {
+				case saga.RemoteStep:
+					builder.Remote(s)
+				case saga.LocalStep:
+					builder.Local(s)
+				}
+			}
+			for _, hook := range []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompleted, saga.SagaCompensated,
+				saga.StepExecuting, saga.ReplyReceived, saga.StepFailed, saga.CompensationStarted, saga.SagaFailed} {
+				builder.OnHook(hook, record)
+			}
+
+			definition, err := builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			publisher := &recordingPublisher{}
+			orchestrator := saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), publisher)
+
+			ctx := context.Background()
+			if _, err = orchestrator.Start(ctx, &orderData{}); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			for _, reply := range tt.replies {
+				sent := publisher.sent()
+				_ = orchestrator.ReceiveMessage(ctx, replyTo(sent[len(sent)-1].message, reply))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hooks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_AbortOnHookError(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	hookErr := errors.New("step is not allowed")
+
+	tests := map[string]struct {
+		abort     bool
+		wantErr   error
+		wantSent  []string
+		wantState saga.InstanceState
+		wantStep  int
+	}{
+		"Logged": {
+			abort:     false,
+			wantSent:  []string{"saga_test.reserveCredit", "saga_test.approveOrder"},
+			wantState: saga.InstanceRunning,
+			wantStep:  1,
+		},
+		"Aborted": {
+			abort:     true,
+			wantErr:   hookErr,
+			wantSent:  []string{"saga_test.reserveCredit", "saga_test.approveOrder"},
+			wantState: saga.InstanceRunning,
+			wantStep:  1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			definition, err := saga.NewDefinition("orderSaga", "orderSaga.reply").
+				Remote(saga.NewRemoteStep().
+					Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} })).
+				Remote(saga.NewRemoteStep().
+					Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} })).
+				OnStepExecuting(func(_ context.Context, info saga.HookInfo) error {
+					if info.Step == 1 {
+						return hookErr
+					}
+					return nil
+				}).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			publisher := &recordingPublisher{}
+			store := inmem.NewSagaInstanceStore()
+			orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorAbortOnHookError(tt.abort))
+
+			ctx := context.Background()
+			instance, err := orchestrator.Start(ctx, &orderData{})
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReceiveMessage() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var sent []string
+			for _, command := range publisher.sent() {
+				sent = append(sent, command.command.CommandName())
+			}
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("commands sent = %v, want %v", sent, tt.wantSent)
+			}
+
+			found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+			if found.State() != tt.wantState || found.CurrentStep() != tt.wantStep {
+				t.Errorf("instance = %v at step %d, want %v at step %d", found.State(), found.CurrentStep(), tt.wantState, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_HooksAfterConflict(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	var executing []int
+	var replies int
+	definition, err := saga.NewDefinition("orderSaga", "orderSaga.reply").
+		Remote(saga.NewRemoteStep().
+			Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} })).
+		Remote(saga.NewRemoteStep().
+			Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} })).
+		OnStepExecuting(func(_ context.Context, info saga.HookInfo) error {
+			executing = append(executing, info.Step)
+			return nil
+		}).
+		OnReplyReceived(func(context.Context, saga.HookInfo) error {
+			replies++
+			return nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	store := &conflictingStore{SagaInstanceStore: inmem.NewSagaInstanceStore()}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorConflictRetries(2))
+
+	ctx := context.Background()
+	if _, err = orchestrator.Start(ctx, &orderData{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	store.conflicts = true
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+	if !errors.Is(err, saga.ErrInstanceConflict) {
+		t.Fatalf("ReceiveMessage() error = %v, want %v", err, saga.ErrInstanceConflict)
+	}
+
+	// the reply and the step that were never saved are not reported
+	if want := []int{0}; !reflect.DeepEqual(executing, want) {
+		t.Errorf("StepExecuting steps = %v, want %v", executing, want)
+	}
+	if replies != 0 {
+		t.Errorf("ReplyReceived calls = %d, want 0", replies)
+	}
+}
+
+func TestOrchestrator_AbortSagaStarting(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	hookErr := errors.New("saga is not allowed")
+
+	definition, err := saga.NewDefinition("orderSaga", "orderSaga.reply").
+		Remote(saga.NewRemoteStep().
+			Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} })).
+		OnHook(saga.SagaStarting, func(context.Context, saga.HookInfo) error { return hookErr }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorAbortOnHookError(true))
+
+	ctx := context.Background()
+	if _, err = orchestrator.Start(ctx, &orderData{}); !errors.Is(err, hookErr) {
+		t.Fatalf("Start() error = %v, want %v", err, hookErr)
+	}
+
+	page, err := store.Query(ctx, saga.InstanceQuery{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if page.Total != 0 || len(publisher.sent()) != 0 {
+		t.Errorf("instances = %d, commands sent = %d, want none", page.Total, len(publisher.sent()))
+	}
+}
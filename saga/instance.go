@@ -1,22 +1,34 @@
 package saga
 
 import (
+	"time"
+
 	"github.com/stackus/edat/core"
 )
 
 // Instance is the container for saga data
 type Instance struct {
-	sagaID       string
-	sagaName     string
-	sagaData     core.SagaData
-	currentStep  int
-	endState     bool
-	compensating bool
+	sagaID         string
+	sagaName       string
+	sagaData       core.SagaData
+	currentStep    int
+	endState       bool
+	compensating   bool
+	failed         bool
+	retries        int
+	branches       map[int]BranchStatus
+	stepID         string
+	deadline       time.Time
+	correlationKey string
+	version        int
+	createdAt      time.Time
+	updatedAt      time.Time
+	interventions  []Intervention
 }
 
 // NewSagaInstance constructor for *SagaInstances
-func NewSagaInstance(sagaName, sagaID string, sagaData core.SagaData, currentStep int, endState, compensating bool) *Instance {
-	return &Instance{
+func NewSagaInstance(sagaName, sagaID string, sagaData core.SagaData, currentStep int, endState, compensating bool, options ...InstanceOption) *Instance {
+	i := &Instance{
 		sagaID:       sagaID,
 		sagaName:     sagaName,
 		sagaData:     sagaData,
@@ -24,6 +36,12 @@ func NewSagaInstance(sagaName, sagaID string, sagaData core.SagaData, currentSte
 		endState:     endState,
 		compensating: compensating,
 	}
+
+	for _, option := range options {
+		option(i)
+	}
+
+	return i
 }
 
 // SagaID returns the instance saga id
@@ -56,11 +74,86 @@ func (i *Instance) Compensating() bool {
 	return i.compensating
 }
 
+// Failed returns whether or not the instance failed while compensating and cannot continue
+func (i *Instance) Failed() bool {
+	return i.failed
+}
+
+// State returns the overall state of the instance
+func (i *Instance) State() InstanceState {
+	switch {
+	case i.failed:
+		return InstanceFailed
+	case i.endState && i.compensating:
+		return InstanceCompensated
+	case i.endState:
+		return InstanceCompleted
+	case i.compensating:
+		return InstanceCompensating
+	default:
+		return InstanceRunning
+	}
+}
+
+// CreatedAt returns the time the instance was started
+func (i *Instance) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+// UpdatedAt returns the time the instance was last saved
+func (i *Instance) UpdatedAt() time.Time {
+	return i.updatedAt
+}
+
+// Interventions returns the manual operations that have been performed on the instance
+func (i *Instance) Interventions() []Intervention {
+	return append([]Intervention{}, i.interventions...)
+}
+
+// Retries returns the number of times the current step has been retried
+func (i *Instance) Retries() int {
+	return i.retries
+}
+
+// Branches returns the status of each branch when the current step is a ParallelStep
+func (i *Instance) Branches() map[int]BranchStatus {
+	branches := make(map[int]BranchStatus, len(i.branches))
+	for branch, status := range i.branches {
+		branches[branch] = status
+	}
+
+	return branches
+}
+
+// StepID returns the id of the commands sent for the current step
+//
+// Replies that were sent for any other step id are ignored
+func (i *Instance) StepID() string {
+	return i.stepID
+}
+
+// Deadline returns the time the current step must reply by, or the zero time when the step has no timeout
+func (i *Instance) Deadline() time.Time {
+	return i.deadline
+}
+
+// Version returns the version of the instance that was loaded from the InstanceStore
+func (i *Instance) Version() int {
+	return i.version
+}
+
+// CorrelationKey returns the key of the event the current EventStep is waiting for
+func (i *Instance) CorrelationKey() string {
+	return i.correlationKey
+}
+
 func (i *Instance) getStepContext() stepContext {
 	return stepContext{
 		step:         i.currentStep,
 		compensating: i.compensating,
 		ended:        i.endState,
+		retries:      i.retries,
+		branches:     i.branches,
 	}
 }
 
@@ -68,4 +161,6 @@ func (i *Instance) updateStepContext(stepCtx stepContext) {
 	i.currentStep = stepCtx.step
 	i.endState = stepCtx.ended
 	i.compensating = stepCtx.compensating
+	i.retries = stepCtx.retries
+	i.branches = stepCtx.branches
 }
@@ -0,0 +1,78 @@
+package saga
+
+import (
+	"time"
+)
+
+// InstanceOption options for Instance
+type InstanceOption func(*Instance)
+
+// WithInstanceFailed is an option to set whether or not the instance failed while compensating
+func WithInstanceFailed(failed bool) InstanceOption {
+	return func(instance *Instance) {
+		instance.failed = failed
+	}
+}
+
+// WithInstanceCreatedAt is an option to set the time the instance was started
+func WithInstanceCreatedAt(createdAt time.Time) InstanceOption {
+	return func(instance *Instance) {
+		instance.createdAt = createdAt
+	}
+}
+
+// WithInstanceUpdatedAt is an option to set the time the instance was last saved
+func WithInstanceUpdatedAt(updatedAt time.Time) InstanceOption {
+	return func(instance *Instance) {
+		instance.updatedAt = updatedAt
+	}
+}
+
+// WithInstanceInterventions is an option to set the manual operations that have been performed on the instance
+func WithInstanceInterventions(interventions []Intervention) InstanceOption {
+	return func(instance *Instance) {
+		instance.interventions = interventions
+	}
+}
+
+// WithInstanceRetries is an option to set the number of times the current step has been retried
+func WithInstanceRetries(retries int) InstanceOption {
+	return func(instance *Instance) {
+		instance.retries = retries
+	}
+}
+
+// WithInstanceBranches is an option to set the status of each branch of the current ParallelStep
+func WithInstanceBranches(branches map[int]BranchStatus) InstanceOption {
+	return func(instance *Instance) {
+		instance.branches = branches
+	}
+}
+
+// WithInstanceStepID is an option to set the id of the commands sent for the current step
+func WithInstanceStepID(stepID string) InstanceOption {
+	return func(instance *Instance) {
+		instance.stepID = stepID
+	}
+}
+
+// WithInstanceVersion is an option to set the version of the instance that was loaded from the InstanceStore
+func WithInstanceVersion(version int) InstanceOption {
+	return func(instance *Instance) {
+		instance.version = version
+	}
+}
+
+// WithInstanceCorrelationKey is an option to set the key of the event the current EventStep is waiting for
+func WithInstanceCorrelationKey(correlationKey string) InstanceOption {
+	return func(instance *Instance) {
+		instance.correlationKey = correlationKey
+	}
+}
+
+// WithInstanceDeadline is an option to set the time the current step must reply by
+func WithInstanceDeadline(deadline time.Time) InstanceOption {
+	return func(instance *Instance) {
+		instance.deadline = deadline
+	}
+}
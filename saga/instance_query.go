@@ -0,0 +1,97 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// InstanceState is the overall state of a saga instance
+type InstanceState string
+
+// Instance states
+const (
+	InstanceRunning      InstanceState = "running"
+	InstanceCompensating InstanceState = "compensating"
+	InstanceCompleted    InstanceState = "completed"
+	InstanceCompensated  InstanceState = "compensated"
+	InstanceFailed       InstanceState = "failed"
+)
+
+// InstanceStates is every InstanceState
+var InstanceStates = []InstanceState{
+	InstanceRunning,
+	InstanceCompensating,
+	InstanceCompleted,
+	InstanceCompensated,
+	InstanceFailed,
+}
+
+// InstanceQuery is the filter and page of instances to return from InstanceQuerier.Query
+//
+// Zero values do not filter the instances. A zero Limit returns all of the remaining instances. Instances without a
+// deadline never match a DeadlineBefore filter
+type InstanceQuery struct {
+	SagaName       string
+	States         []InstanceState
+	CreatedBefore  time.Time
+	CreatedAfter   time.Time
+	UpdatedBefore  time.Time
+	DeadlineBefore time.Time
+	Offset         int
+	Limit          int
+}
+
+// InstancePage is a page of instances returned from InstanceQuerier.Query
+//
+// Total is the number of instances that matched the query before it was paginated
+type InstancePage struct {
+	Instances []*Instance
+	Total     int
+}
+
+// InstanceQuerier is an optional interface that InstanceStores may implement to search for instances
+//
+// Instances are returned ordered by the time they were created, oldest first
+type InstanceQuerier interface {
+	Query(ctx context.Context, query InstanceQuery) (InstancePage, error)
+	// Counts returns the number of instances in each state; All sagas are counted when sagaName is blank
+	Counts(ctx context.Context, sagaName string) (map[InstanceState]int, error)
+}
+
+// Matches reports whether the instance passes the filters of the query
+func (q InstanceQuery) Matches(instance *Instance) bool {
+	if q.SagaName != "" && instance.SagaName() != q.SagaName {
+		return false
+	}
+
+	if len(q.States) > 0 {
+		matched := false
+		for _, state := range q.States {
+			if instance.State() == state {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if !q.CreatedBefore.IsZero() && !instance.CreatedAt().Before(q.CreatedBefore) {
+		return false
+	}
+
+	if !q.CreatedAfter.IsZero() && !instance.CreatedAt().After(q.CreatedAfter) {
+		return false
+	}
+
+	if !q.UpdatedBefore.IsZero() && !instance.UpdatedAt().Before(q.UpdatedBefore) {
+		return false
+	}
+
+	if !q.DeadlineBefore.IsZero() && (instance.Deadline().IsZero() || !instance.Deadline().Before(q.DeadlineBefore)) {
+		return false
+	}
+
+	return true
+}
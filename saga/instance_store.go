@@ -2,11 +2,46 @@ package saga
 
 import (
 	"context"
+	"errors"
+	"fmt"
 )
 
+// ErrInstanceConflict is matched by InstanceConflictError
+var ErrInstanceConflict = errors.New("saga instance has been updated by another process")
+
+// InstanceConflictError is returned by InstanceStore.Update when the version of the instance is stale
+type InstanceConflictError struct {
+	SagaName      string
+	SagaID        string
+	Version       int
+	StoredVersion int
+}
+
+// Error implements error.Error
+func (e *InstanceConflictError) Error() string {
+	return fmt.Sprintf("%s: %s %s is at version %d, got %d", ErrInstanceConflict, e.SagaName, e.SagaID, e.StoredVersion, e.Version)
+}
+
+// Is returns whether or not the target is ErrInstanceConflict
+func (e *InstanceConflictError) Is(target error) bool {
+	return target == ErrInstanceConflict
+}
+
 // InstanceStore interface
+//
+// Update must only save the instance when the stored version matches Instance.Version, saving it with the next
+// version, and otherwise return an InstanceConflictError
 type InstanceStore interface {
 	Find(ctx context.Context, sagaName, sagaID string) (*Instance, error)
 	Save(ctx context.Context, sagaInstance *Instance) error
 	Update(ctx context.Context, sagaInstance *Instance) error
 }
+
+// InstanceCorrelator is implemented by instance stores that can locate the instance an EventStep is waiting on
+//
+// FindByCorrelationKey returns every running instance of the saga that is waiting with the correlation key. Several
+// instances, or several steps of the saga, may use the same key; the Orchestrator selects the instances that are
+// waiting on the step the event is for
+type InstanceCorrelator interface {
+	FindByCorrelationKey(ctx context.Context, sagaName, correlationKey string) ([]*Instance, error)
+}
@@ -0,0 +1,245 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
+	"github.com/stackus/edat/msg"
+)
+
+// InterventionAction is a manual operation that can be performed on a saga instance
+type InterventionAction string
+
+// Intervention actions
+const (
+	InterventionAbort      InterventionAction = "abort"
+	InterventionRetryStep  InterventionAction = "retry"
+	InterventionSkipStep   InterventionAction = "skip"
+	InterventionMarkFailed InterventionAction = "fail"
+)
+
+// Intervention is the record of a manual operation performed on a saga instance
+type Intervention struct {
+	Action   InterventionAction
+	Operator string
+	Reason   string
+	Step     int
+	State    InstanceState
+	At       time.Time
+}
+
+// ErrInstanceNotFound is returned when the saga instance does not exist
+var ErrInstanceNotFound = errors.New("saga instance not found")
+
+// ErrInterventionIncomplete is returned when an intervention is missing the operator or reason
+var ErrInterventionIncomplete = errors.New("intervention requires an operator and reason")
+
+// ErrInterventionNotAllowed is returned when an intervention cannot be performed in the current instance state
+var ErrInterventionNotAllowed = errors.New("intervention is not allowed")
+
+// Abort stops a running saga and compensates the steps that have completed
+func (o *Orchestrator) Abort(ctx context.Context, sagaID, operator, reason string) (*Instance, error) {
+	allowed := []InstanceState{InstanceRunning}
+
+	return o.intervene(ctx, sagaID, InterventionAbort, operator, reason, allowed, func(instance *Instance) (*stepResults, error) {
+		stepCtx := instance.getStepContext()
+
+		if parallel, ok := o.definition.Steps()[stepCtx.step].(ParallelStep); ok {
+			reply := msg.NewReply(Aborted{}, msg.Headers{
+				msg.MessageReplyOutcome: msg.ReplyOutcomeFailure,
+				msg.MessageReplyName:    Aborted{}.ReplyName(),
+			})
+
+			return o.handleBranchReply(ctx, parallel, stepCtx, instance.sagaData, reply, o.logger)
+		}
+
+		return o.executeNextStep(ctx, stepCtx.compensate(), instance.sagaData), nil
+	})
+}
+
+// RetryStep sends the command of the current step again
+//
+// A failed saga will resume compensating by sending the compensation command of the step that failed again, or
+// resume running by sending the command of a step that could not be sent
+func (o *Orchestrator) RetryStep(ctx context.Context, sagaID, operator, reason string) (*Instance, error) {
+	allowed := []InstanceState{InstanceRunning, InstanceCompensating, InstanceFailed}
+
+	return o.intervene(ctx, sagaID, InterventionRetryStep, operator, reason, allowed, func(instance *Instance) (*stepResults, error) {
+		stepCtx := instance.getStepContext()
+		step := o.definition.Steps()[stepCtx.step]
+
+		// the branches are copied so that the instance is not changed unless the results are processed
+		branches := make(map[int]BranchStatus, len(stepCtx.branches))
+		for branch, status := range stepCtx.branches {
+			branches[branch] = status
+		}
+		stepCtx.branches = branches
+
+		results := &stepResults{
+			updatedSagaData:    instance.sagaData,
+			updatedStepContext: stepCtx,
+		}
+
+		if parallel, ok := step.(ParallelStep); ok {
+			parallel.executeBranches(ctx, instance.sagaData, stepCtx.compensating, func(branch int) bool {
+				return branches[branch] == BranchPending || branches[branch] == BranchCompensating
+			})(results)
+		} else if step.hasInvocableAction(ctx, instance.sagaData, stepCtx.compensating) {
+			step.execute(ctx, instance.sagaData, stepCtx.compensating)(results)
+		}
+
+		if len(results.commands) == 0 && !results.local {
+			return nil, fmt.Errorf("%w: the current step has nothing to retry", ErrInterventionNotAllowed)
+		}
+
+		o.resume(instance)
+
+		return results, nil
+	})
+}
+
+// SkipStep continues the saga as if the current step had succeeded
+func (o *Orchestrator) SkipStep(ctx context.Context, sagaID, operator, reason string) (*Instance, error) {
+	allowed := []InstanceState{InstanceRunning, InstanceCompensating, InstanceFailed}
+
+	return o.intervene(ctx, sagaID, InterventionSkipStep, operator, reason, allowed, func(instance *Instance) (*stepResults, error) {
+		o.resume(instance)
+
+		return o.executeNextStep(ctx, instance.getStepContext(), instance.sagaData), nil
+	})
+}
+
+// MarkFailed stops the saga without compensating; No more replies will be processed for the instance
+func (o *Orchestrator) MarkFailed(ctx context.Context, sagaID, operator, reason string) (*Instance, error) {
+	allowed := []InstanceState{InstanceRunning, InstanceCompensating}
+
+	return o.intervene(ctx, sagaID, InterventionMarkFailed, operator, reason, allowed, func(instance *Instance) (*stepResults, error) {
+		info := o.hookInfo(SagaFailed, instance)
+		info.Err = fmt.Errorf("saga marked failed by %s: %s", operator, reason)
+
+		err := o.fireHook(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+
+		instance.failed = true
+		instance.deadline = time.Time{}
+
+		return nil, nil
+	})
+}
+
+func (o *Orchestrator) intervene(ctx context.Context, sagaID string, action InterventionAction, operator, reason string, allowed []InstanceState, fn func(*Instance) (*stepResults, error)) (*Instance, error) {
+	if operator == "" || reason == "" {
+		return nil, ErrInterventionIncomplete
+	}
+
+	logger := o.logger.Sub(
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("SagaID", sagaID),
+		log.String("Action", string(action)),
+		log.String("Operator", operator),
+	)
+
+	instance, err := o.instanceStore.Find(ctx, o.definition.SagaName(), sagaID)
+	if err != nil {
+		logger.Error("failed to locate saga instance data", log.Error(err))
+		return nil, err
+	}
+
+	if instance == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, sagaID)
+	}
+
+	state := instance.State()
+	if !hasState(allowed, state) {
+		return nil, fmt.Errorf("%w: cannot %s a %s saga", ErrInterventionNotAllowed, action, state)
+	}
+
+	if instance.currentStep < 0 || instance.currentStep >= len(o.definition.Steps()) {
+		return nil, fmt.Errorf("%w: current step is out of bounds", ErrInterventionNotAllowed)
+	}
+
+	logger.Info("saga intervention", log.String("Reason", reason), log.String("State", string(state)))
+
+	instance.interventions = append(instance.Interventions(), Intervention{
+		Action:   action,
+		Operator: operator,
+		Reason:   reason,
+		Step:     instance.currentStep,
+		State:    state,
+		At:       o.clock.Now(),
+	})
+
+	// the intervention is recorded once it has been performed and saved
+	performed := stampRecord(instance, HistoryRecord{
+		Type:   HistoryIntervention,
+		Name:   string(action),
+		Detail: operator + ": " + reason,
+	})
+
+	results, err := fn(instance)
+	if err != nil {
+		logger.Error("error performing saga intervention", log.Error(err))
+		return nil, err
+	}
+
+	if results == nil {
+		instance.updatedAt = o.clock.Now()
+
+		err = o.updateInstance(ctx, instance)
+		if err != nil {
+			logger.Error("error saving saga instance", log.Error(err))
+			return nil, err
+		}
+
+		o.record(ctx, instance, performed)
+
+		o.trackFailure(state == InstanceFailed, instance)
+
+		return instance, nil
+	}
+
+	err = o.processResults(ctx, instance, results, nil, performed)
+	if err != nil {
+		logger.Error("error while processing results", log.Error(err))
+		// a resumed instance that failed again was never counted as active again
+		if state == InstanceFailed && errors.Is(err, ErrCommandPublishFailed) {
+			o.active.Add(1, metrics.Labels{"saga": o.definition.SagaName()})
+		}
+		return nil, err
+	}
+
+	o.trackFailure(state == InstanceFailed, instance)
+
+	return instance, nil
+}
+
+// resume returns a failed instance to the active sagas
+func (o *Orchestrator) resume(instance *Instance) {
+	instance.failed = false
+}
+
+// trackFailure updates the active sagas once an instance that has failed or resumed has been saved
+func (o *Orchestrator) trackFailure(wasFailed bool, instance *Instance) {
+	switch {
+	case !wasFailed && instance.failed:
+		o.active.Add(-1, metrics.Labels{"saga": o.definition.SagaName()})
+	case wasFailed && !instance.failed:
+		o.active.Add(1, metrics.Labels{"saga": o.definition.SagaName()})
+	}
+}
+
+func hasState(states []InstanceState, state InstanceState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,234 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+	"github.com/stackus/edat/saga/sagatest"
+)
+
+func TestOrchestrator_Interventions(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	type operation func(o *saga.Orchestrator) func(context.Context, string, string, string) (*saga.Instance, error)
+	abort := func(o *saga.Orchestrator) func(context.Context, string, string, string) (*saga.Instance, error) {
+		return o.Abort
+	}
+	retryStep := func(o *saga.Orchestrator) func(context.Context, string, string, string) (*saga.Instance, error) {
+		return o.RetryStep
+	}
+	skipStep := func(o *saga.Orchestrator) func(context.Context, string, string, string) (*saga.Instance, error) {
+		return o.SkipStep
+	}
+	markFailed := func(o *saga.Orchestrator) func(context.Context, string, string, string) (*saga.Instance, error) {
+		return o.MarkFailed
+	}
+
+	type want struct {
+		err   error
+		sent  []string
+		state saga.InstanceState
+	}
+	tests := map[string]struct {
+		// replies are sent to the most recent command before the intervention is performed
+		replies   []msg.Reply
+		parallel  bool
+		operation operation
+		operator  string
+		want      want
+	}{
+		"AbortRunning": {
+			replies:   []msg.Reply{msg.WithSuccess()},
+			operation: abort,
+			want:      want{sent: []string{"saga_test.releaseCredit"}, state: saga.InstanceCompensating},
+		},
+		"AbortFirstStep": {
+			operation: abort,
+			want:      want{state: saga.InstanceCompensated},
+		},
+		"AbortParallel": {
+			parallel:  true,
+			operation: abort,
+			want:      want{sent: []string{"saga_test.cancelHotel"}, state: saga.InstanceCompensating},
+		},
+		"AbortCompensating": {
+			replies:   []msg.Reply{msg.WithSuccess(), msg.WithFailure()},
+			operation: abort,
+			want:      want{err: saga.ErrInterventionNotAllowed, state: saga.InstanceCompensating},
+		},
+		"MissingOperator": {
+			operation: abort,
+			operator:  "-",
+			want:      want{err: saga.ErrInterventionIncomplete, state: saga.InstanceRunning},
+		},
+		"RetryRunning": {
+			replies:   []msg.Reply{msg.WithSuccess()},
+			operation: retryStep,
+			want:      want{sent: []string{"saga_test.approveOrder"}, state: saga.InstanceRunning},
+		},
+		"RetryFailed": {
+			replies:   []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithFailure()},
+			operation: retryStep,
+			want:      want{sent: []string{"saga_test.releaseCredit"}, state: saga.InstanceCompensating},
+		},
+		"RetryCompleted": {
+			replies:   []msg.Reply{msg.WithSuccess(), msg.WithSuccess()},
+			operation: retryStep,
+			want:      want{err: saga.ErrInterventionNotAllowed, state: saga.InstanceCompleted},
+		},
+		"SkipRunning": {
+			replies:   []msg.Reply{msg.WithSuccess()},
+			operation: skipStep,
+			want:      want{state: saga.InstanceCompleted},
+		},
+		"SkipFailed": {
+			replies:   []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithFailure()},
+			operation: skipStep,
+			want:      want{state: saga.InstanceCompensated},
+		},
+		"MarkFailedRunning": {
+			operation: markFailed,
+			want:      want{state: saga.InstanceFailed},
+		},
+		"MarkFailedFailed": {
+			replies:   []msg.Reply{msg.WithSuccess(), msg.WithFailure(), msg.WithFailure()},
+			operation: markFailed,
+			want:      want{err: saga.ErrInterventionNotAllowed, state: saga.InstanceFailed},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+			publisher := &recordingPublisher{}
+			store := inmem.NewSagaInstanceStore()
+
+			first := saga.Step(saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }))
+			if tt.parallel {
+				first = saga.NewParallelStep(bookingStep("Hotel"), bookingStep("Car"))
+			}
+
+			definition := &testDefinition{
+				steps: []saga.Step{
+					first,
+					saga.NewRemoteStep().
+						Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+				},
+			}
+
+			orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+			ctx := context.Background()
+			instance, err := orchestrator.Start(ctx, &orderData{})
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			if tt.parallel {
+				hotel := publisher.sent()[0]
+				if hotel.command.CommandName() != "saga_test.reserveHotel" {
+					hotel = publisher.sent()[1]
+				}
+				_ = orchestrator.ReceiveMessage(ctx, replyTo(hotel.message, msg.WithSuccess()))
+			}
+
+			for _, reply := range tt.replies {
+				sent := publisher.sent()
+				_ = orchestrator.ReceiveMessage(ctx, replyTo(sent[len(sent)-1].message, reply))
+			}
+
+			before := len(publisher.sent())
+
+			operator := "operator-id"
+			if tt.operator != "" {
+				operator = ""
+			}
+
+			_, err = tt.operation(orchestrator)(ctx, instance.SagaID(), operator, "testing")
+			if !errors.Is(err, tt.want.err) {
+				t.Fatalf("intervention error = %v, want %v", err, tt.want.err)
+			}
+
+			var sent []string
+			for _, command := range publisher.sent()[before:] {
+				sent = append(sent, command.command.CommandName())
+			}
+			if !reflect.DeepEqual(sent, tt.want.sent) {
+				t.Errorf("commands sent = %v, want %v", sent, tt.want.sent)
+			}
+
+			found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+			if found.State() != tt.want.state {
+				t.Errorf("State() = %v, want %v", found.State(), tt.want.state)
+			}
+
+			interventions := found.Interventions()
+			if tt.want.err != nil {
+				if len(interventions) != 0 {
+					t.Errorf("Interventions() = %v, want none", interventions)
+				}
+				return
+			}
+			if len(interventions) != 1 || interventions[0].Operator != "operator-id" || interventions[0].Reason != "testing" || !interventions[0].At.Equal(clock.Now()) {
+				t.Errorf("Interventions() = %v, want the intervention to be recorded", interventions)
+			}
+		})
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		orchestrator := saga.NewOrchestrator(&testDefinition{}, inmem.NewSagaInstanceStore(), &recordingPublisher{})
+		_, err := orchestrator.Abort(context.Background(), "saga-id", "operator-id", "testing")
+		if !errors.Is(err, saga.ErrInstanceNotFound) {
+			t.Errorf("Abort() error = %v, want %v", err, saga.ErrInstanceNotFound)
+		}
+	})
+}
+
+func TestOrchestrator_RetryStepIgnoresLateReply(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher)
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	original := publisher.sent()[0].message
+
+	if _, err = orchestrator.RetryStep(ctx, instance.SagaID(), "operator-id", "participant lost the command"); err != nil {
+		t.Fatalf("RetryStep() error = %v", err)
+	}
+
+	// the reply to the original command is late and ignored
+	_ = orchestrator.ReceiveMessage(ctx, replyTo(original, msg.WithFailure()))
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceRunning {
+		t.Fatalf("State() = %v, want %v", found.State(), saga.InstanceRunning)
+	}
+
+	_ = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[1].message, msg.WithSuccess()))
+	found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceCompleted {
+		t.Errorf("State() = %v, want %v", found.State(), saga.InstanceCompleted)
+	}
+}
@@ -36,6 +36,10 @@ func (s LocalStep) getReplyHandler(string, bool) func(context.Context, core.Saga
 	return nil
 }
 
+func (s LocalStep) getRetryPolicy(bool) *RetryPolicy {
+	return nil
+}
+
 func (s LocalStep) execute(ctx context.Context, sagaData core.SagaData, compensating bool) func(results *stepResults) {
 	err := s.actions[compensating](ctx, sagaData)
 	return func(results *stepResults) {
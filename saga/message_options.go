@@ -6,8 +6,14 @@ import (
 
 // WithSagaInfo is an option to set additional Saga specific headers
 func WithSagaInfo(instance *Instance) msg.MessageOption {
-	return msg.WithHeaders(map[string]string{
+	headers := map[string]string{
 		MessageCommandSagaID:   instance.sagaID,
 		MessageCommandSagaName: instance.sagaName,
-	})
+	}
+
+	if instance.stepID != "" {
+		headers[MessageCommandSagaStepID] = instance.stepID
+	}
+
+	return msg.WithHeaders(headers)
 }
@@ -2,40 +2,107 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 	"github.com/stackus/edat/msg"
 )
 
 // Orchestrator orchestrates local and distributed processes
 type Orchestrator struct {
-	definition    Definition
-	instanceStore InstanceStore
-	publisher     msg.CommandMessagePublisher
-	logger        log.Logger
+	definition        Definition
+	instanceStore     InstanceStore
+	history           HistoryStore
+	publisher         msg.CommandMessagePublisher
+	clock             Clock
+	logger            log.Logger
+	registry          metrics.Registry
+	conflictRetries   int
+	abortOnHookErr    bool
+	scheduledDelivery bool
+	started           metrics.Counter
+	completed         metrics.Counter
+	compensated       metrics.Counter
+	active            metrics.Gauge
 }
 
 const sagaNotStarted = -1
 
+// DefaultConflictRetries is the number of times a message is processed again with the reloaded instance when
+// another process has updated the instance first
+const DefaultConflictRetries = 10
+
+// ErrInstanceStoreNotCorrelator is returned when a saga with an EventStep uses an InstanceStore that does not
+// implement InstanceCorrelator
+var ErrInstanceStoreNotCorrelator = errors.New("saga instance store does not implement InstanceCorrelator")
+
+// ErrCompensationFailed is returned when a step fails while the saga is compensating
+//
+// The saga instance is marked as failed and will not process any more replies
+var ErrCompensationFailed = errors.New("received failure outcome while compensating")
+
+// ErrCommandPublishFailed is returned when the commands of a step could not be sent after the step has been saved
+//
+// The saga instance is marked as failed; RetryStep sends the commands of the step again
+var ErrCommandPublishFailed = errors.New("saga step commands could not be sent")
+
 var _ msg.MessageReceiver = (*Orchestrator)(nil)
 
+// NewValidatedOrchestrator constructs a new Orchestrator and returns an error when the InstanceStore cannot be used with
+// the steps of the definition
+//
+// Definitions with an EventStep require an InstanceStore that implements InstanceCorrelator, otherwise
+// ErrInstanceStoreNotCorrelator is returned. ErrBranchRetryPolicy is returned for the branches of a ParallelStep that
+// have a retry policy
+func NewValidatedOrchestrator(definition Definition, store InstanceStore, publisher msg.CommandMessagePublisher, options ...OrchestratorOption) (*Orchestrator, error) {
+	if _, ok := store.(InstanceCorrelator); !ok && len(eventSteps(definition)) > 0 {
+		return nil, fmt.Errorf("saga `%s`: %w", definition.SagaName(), ErrInstanceStoreNotCorrelator)
+	}
+
+	for i, step := range definition.Steps() {
+		if parallel, ok := step.(ParallelStep); ok {
+			if errs := parallel.branchRetryPolicies(fmt.Sprintf("step %d", i)); len(errs) > 0 {
+				return nil, fmt.Errorf("saga `%s`: %w", definition.SagaName(), errs[0])
+			}
+		}
+	}
+
+	return NewOrchestrator(definition, store, publisher, options...), nil
+}
+
 // NewOrchestrator constructs a new Orchestrator
+//
+// The InstanceStore is not checked; see NewValidatedOrchestrator. Events received for an EventStep return
+// ErrInstanceStoreNotCorrelator when the InstanceStore does not implement InstanceCorrelator
 func NewOrchestrator(definition Definition, store InstanceStore, publisher msg.CommandMessagePublisher, options ...OrchestratorOption) *Orchestrator {
 	o := &Orchestrator{
-		definition:    definition,
-		instanceStore: store,
-		publisher:     publisher,
-		logger:        log.DefaultLogger,
+		definition:        definition,
+		instanceStore:     store,
+		publisher:         publisher,
+		clock:             SystemClock,
+		logger:            log.DefaultLogger,
+		registry:          metrics.DefaultRegistry,
+		conflictRetries:   DefaultConflictRetries,
+		scheduledDelivery: true,
 	}
 
 	for _, option := range options {
 		option(o)
 	}
 
+	o.started = o.registry.Counter(metrics.SagasStarted, "Number of sagas started")
+	o.completed = o.registry.Counter(metrics.SagasCompleted, "Number of sagas completed")
+	o.compensated = o.registry.Counter(metrics.SagasCompensated, "Number of sagas compensated")
+	o.active = o.registry.Gauge(metrics.SagasActive, "Number of sagas that have not ended")
+
 	o.logger.Trace("saga.Orchestrator constructed", log.String("SagaName", definition.SagaName()))
 
 	return o
@@ -49,26 +116,40 @@ func (o *Orchestrator) Start(ctx context.Context, sagaData core.SagaData) (*Inst
 		sagaData: sagaData,
 	}
 
-	err := o.instanceStore.Save(ctx, instance)
-	if err != nil {
-		return nil, err
-	}
+	instance.createdAt = o.clock.Now()
+	instance.updatedAt = instance.createdAt
 
 	logger := o.logger.Sub(
 		log.String("SagaName", o.definition.SagaName()),
 		log.String("SagaID", instance.sagaID),
 	)
 
+	// the hook is fired before the instance is saved so that nothing is left behind when it stops the saga
 	logger.Trace("executing saga starting hook")
-	o.definition.OnHook(SagaStarting, instance)
+	err := o.fireHook(ctx, o.hookInfo(SagaStarting, instance))
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.instanceStore.Save(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	o.record(ctx, instance, HistoryRecord{Type: HistoryHookFired, Name: SagaStarting.String()})
 
 	results := o.executeNextStep(ctx, stepContext{step: sagaNotStarted}, sagaData)
 	if results.failure != nil {
 		logger.Error("error while starting saga orchestration", log.Error(results.failure))
-		return nil, err
+		return nil, results.failure
 	}
 
-	err = o.processResults(ctx, instance, results)
+	// sagas that fail to start are not counted as started or active
+	labels := metrics.Labels{"saga": o.definition.SagaName()}
+	o.started.Inc(labels)
+	o.active.Add(1, labels)
+
+	err = o.processResults(ctx, instance, results, nil)
 	if err != nil {
 		logger.Error("error while processing results", log.Error(err))
 		return nil, err
@@ -77,13 +158,36 @@ func (o *Orchestrator) Start(ctx context.Context, sagaData core.SagaData) (*Inst
 	return instance, err
 }
 
+// SagaName returns the name of the saga being orchestrated
+func (o *Orchestrator) SagaName() string {
+	return o.definition.SagaName()
+}
+
 // ReplyChannel returns the channel replies are to be received from msg.Subscribers
 func (o *Orchestrator) ReplyChannel() string {
 	return o.definition.ReplyChannel()
 }
 
+// Subscribe subscribes the Orchestrator to the reply channel and to the channels of every EventStep
+func (o *Orchestrator) Subscribe(subscriber msg.MessageSubscriber) {
+	channels := map[string]struct{}{o.definition.ReplyChannel(): {}}
+	subscriber.Subscribe(o.definition.ReplyChannel(), o)
+
+	for _, step := range eventSteps(o.definition) {
+		if _, exists := channels[step.channel]; exists {
+			continue
+		}
+		channels[step.channel] = struct{}{}
+		subscriber.Subscribe(step.channel, o)
+	}
+}
+
 // ReceiveMessage implements msg.MessageReceiver.ReceiveMessage
 func (o *Orchestrator) ReceiveMessage(ctx context.Context, message msg.Message) error {
+	if message.Headers().Has(msg.MessageEventName) {
+		return o.receiveEvent(ctx, message)
+	}
+
 	replyName, sagaID, sagaName, err := o.replyMessageInfo(message)
 	if err != nil {
 		return nil
@@ -103,7 +207,7 @@ func (o *Orchestrator) ReceiveMessage(ctx context.Context, message msg.Message)
 
 	logger.Debug("received saga reply message")
 
-	reply, err := core.DeserializeReply(replyName, message.Payload())
+	reply, err := core.DeserializeReply(replyName, message.Payload(), message.Headers().Get(msg.MessageContentType))
 	if err != nil {
 		// sagas should not be receiving any replies that have not already been registered
 		logger.Error("error decoding reply message payload", log.Error(err))
@@ -118,23 +222,357 @@ func (o *Orchestrator) ReceiveMessage(ctx context.Context, message msg.Message)
 		return nil
 	}
 
-	stepCtx := instance.getStepContext()
+	if instance == nil {
+		logger.Error("saga instance not found")
+		return nil
+	}
+
+	// the reply is recorded once the transition it causes has been saved
+	received := HistoryRecord{
+		Type:      HistoryReplyReceived,
+		Name:      replyName,
+		MessageID: message.ID(),
+		Outcome:   message.Headers().Get(msg.MessageReplyOutcome),
+		Detail:    branchDetail(message.Headers().Get(MessageReplySagaBranch)),
+	}
+
+	return o.retryOnConflict(ctx, instance, logger, func(instance *Instance) error {
+		if instance.endState || instance.failed {
+			logger.Warn("ignoring reply for a saga that has ended")
+			return nil
+		}
+
+		// replies sent for an earlier step, such as one that has timed out, are late and must be ignored
+		if stepID := message.Headers().Get(MessageReplySagaStepID); stepID != "" && stepID != instance.stepID {
+			logger.Warn("ignoring reply for a previous step", log.String("StepID", stepID))
+			return nil
+		}
+
+		hooks := o.replyHooks(instance, replyName, message.Headers().Get(msg.MessageReplyOutcome))
+
+		stepCtx := instance.getStepContext()
+		received := stampRecord(instance, received)
+
+		results, err := o.handleReply(ctx, stepCtx, instance.SagaData(), replyMsg)
+		if err != nil {
+			logger.Error("saga reply handler returned an error", log.Error(err))
+			return o.processFailure(ctx, instance, err, hooks, received)
+		}
+
+		err = o.processResults(ctx, instance, results, hooks, received)
+		if err != nil {
+			logger.Error("error while processing results", log.Error(err))
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (o *Orchestrator) receiveEvent(ctx context.Context, message msg.Message) error {
+	eventName := message.Headers().Get(msg.MessageEventName)
+
+	logger := o.logger.Sub(
+		log.String("EventName", eventName),
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("MessageID", message.ID()),
+	)
+
+	var event msg.Event
+
+	// an instance is advanced once by an event even when it then waits on another step for the same event
+	handled := map[string]struct{}{}
+
+	for i, step := range o.definition.Steps() {
+		eventStep, ok := step.(EventStep)
+		if !ok || eventStep.eventName != eventName {
+			continue
+		}
+
+		correlator, ok := o.instanceStore.(InstanceCorrelator)
+		if !ok {
+			logger.Error("saga instance store cannot correlate events", log.Error(ErrInstanceStoreNotCorrelator))
+			return ErrInstanceStoreNotCorrelator
+		}
+
+		if event == nil {
+			logger.Debug("received saga event message")
+
+			evt, err := core.DeserializeEvent(eventName, message.Payload(), message.Headers().Get(msg.MessageContentType))
+			if err != nil {
+				logger.Error("error decoding event message payload", log.Error(err))
+				return nil
+			}
+
+			event = msg.NewEvent(evt, message.Headers())
+		}
+
+		key := eventStep.eventKey(ctx, event)
+		if key == "" {
+			continue
+		}
+
+		instances, err := correlator.FindByCorrelationKey(ctx, o.definition.SagaName(), key)
+		if err != nil {
+			logger.Error("failed to locate saga instance data", log.Error(err))
+			return err
+		}
+
+		// the event may be for a different step, or for an instance that is no longer waiting
+		isWaiting := func(instance *Instance) bool {
+			return instance.currentStep == i && instance.correlationKey == key && !instance.compensating && !instance.endState && !instance.failed
+		}
+
+		for _, instance := range instances {
+			if _, exists := handled[instance.sagaID]; exists || !isWaiting(instance) {
+				continue
+			}
+			handled[instance.sagaID] = struct{}{}
+
+			received := HistoryRecord{
+				Type:      HistoryEventReceived,
+				Name:      eventName,
+				MessageID: message.ID(),
+				Detail:    key,
+			}
+
+			err = o.retryOnConflict(ctx, instance, logger, func(instance *Instance) error {
+				if !isWaiting(instance) {
+					return nil
+				}
+
+				return o.handleEvent(ctx, eventStep, instance, event, stampRecord(instance, received))
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) handleEvent(ctx context.Context, step EventStep, instance *Instance, event msg.Event, received HistoryRecord) error {
+	logger := o.logger.Sub(
+		log.String("EventName", step.eventName),
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("SagaID", instance.sagaID),
+		log.String("CorrelationKey", instance.correlationKey),
+	)
+
+	if step.handler != nil {
+		logger.Trace("saga event handler found")
+		err := step.handler(ctx, instance.sagaData, event)
+		if err != nil {
+			logger.Error("saga event handler returned an error", log.Error(err))
+			return err
+		}
+	}
+
+	logger.Trace("advancing to next step")
+	results := o.executeNextStep(ctx, instance.getStepContext(), instance.sagaData)
 
-	results, err := o.handleReply(ctx, stepCtx, instance.SagaData(), replyMsg)
+	err := o.processResults(ctx, instance, results, nil, received)
 	if err != nil {
-		logger.Error("saga reply handler returned an error", log.Error(err))
+		logger.Error("error while processing results", log.Error(err))
 		return err
 	}
 
-	err = o.processResults(ctx, instance, results)
+	return nil
+}
+
+func eventSteps(definition Definition) []EventStep {
+	var steps []EventStep
+	for _, step := range definition.Steps() {
+		if eventStep, ok := step.(EventStep); ok {
+			steps = append(steps, eventStep)
+		}
+	}
+
+	return steps
+}
+
+// ErrInstanceStoreNotQueryable is returned when the InstanceStore does not implement InstanceQuerier
+var ErrInstanceStoreNotQueryable = errors.New("saga instance store does not implement InstanceQuerier")
+
+// CheckTimeout fails the current step of the saga instance when its deadline has passed
+//
+// Timeouts are checked automatically by timers started with the Orchestrator Clock. Those timers only live in the
+// current process; see SweepTimeouts for the deadlines of instances that were waiting when a process stopped
+func (o *Orchestrator) CheckTimeout(ctx context.Context, sagaID string) error {
+	return o.checkTimeout(ctx, sagaID, "")
+}
+
+// CheckTimeouts fails the current step of every instance of the saga whose deadline has passed
+//
+// The InstanceStore must implement InstanceQuerier. Errors for individual instances are logged and do not stop the
+// remaining instances from being checked
+func (o *Orchestrator) CheckTimeouts(ctx context.Context) error {
+	querier, ok := o.instanceStore.(InstanceQuerier)
+	if !ok {
+		return ErrInstanceStoreNotQueryable
+	}
+
+	page, err := querier.Query(ctx, InstanceQuery{
+		SagaName:       o.definition.SagaName(),
+		States:         []InstanceState{InstanceRunning, InstanceCompensating},
+		DeadlineBefore: o.clock.Now(),
+	})
 	if err != nil {
-		logger.Error("error while processing results", log.Error(err))
+		o.logger.Error("error querying for expired saga deadlines", log.Error(err))
+		return err
+	}
+
+	for _, instance := range page.Instances {
+		err = o.checkTimeout(ctx, instance.SagaID(), instance.StepID())
+		if err != nil {
+			o.logger.Error("error handling saga step timeout", log.String("SagaID", instance.SagaID()), log.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// SweepTimeouts calls CheckTimeouts every interval until the context is done
+//
+// Deadlines are kept with the saga instance, but the timers that act on them are held in memory and are lost when
+// the process stops. Running a sweep finds the deadlines that passed while no timer was waiting on them. The sweep
+// queries the InstanceStore, rather than scheduling a message with a msg.Scheduler for each deadline, so that the
+// instance remains the only record of a deadline and timeouts do not depend on a scheduler being configured
+//  go orchestrator.SweepTimeouts(ctx, time.Minute)
+func (o *Orchestrator) SweepTimeouts(ctx context.Context, interval time.Duration) error {
+	if _, ok := o.instanceStore.(InstanceQuerier); !ok {
+		return ErrInstanceStoreNotQueryable
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := o.CheckTimeouts(ctx)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Orchestrator) checkTimeout(ctx context.Context, sagaID, stepID string) error {
+	logger := o.logger.Sub(
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("SagaID", sagaID),
+	)
+
+	instance, err := o.instanceStore.Find(ctx, o.definition.SagaName(), sagaID)
+	if err != nil {
+		logger.Error("failed to locate saga instance data", log.Error(err))
 		return err
 	}
 
+	if instance == nil {
+		return fmt.Errorf("%w: %s", ErrInstanceNotFound, sagaID)
+	}
+
+	return o.retryOnConflict(ctx, instance, logger, func(instance *Instance) error {
+		// the step has already replied, or a different step is now being waited on
+		if instance.endState || instance.failed || instance.deadline.IsZero() || (stepID != "" && stepID != instance.stepID) {
+			return nil
+		}
+
+		if o.clock.Now().Before(instance.deadline) {
+			return nil
+		}
+
+		logger.Warn("saga step has timed out", log.Int("Step", instance.currentStep), log.String("StepID", instance.stepID))
+
+		reply := msg.NewReply(StepTimedOut{}, msg.Headers{
+			msg.MessageReplyOutcome: msg.ReplyOutcomeFailure,
+			msg.MessageReplyName:    StepTimedOut{}.ReplyName(),
+			MessageReplySagaID:      instance.sagaID,
+			MessageReplySagaName:    instance.sagaName,
+			MessageReplySagaStepID:  instance.stepID,
+		})
+
+		received := stampRecord(instance, HistoryRecord{
+			Type:    HistoryReplyReceived,
+			Name:    StepTimedOut{}.ReplyName(),
+			Outcome: msg.ReplyOutcomeFailure,
+		})
+
+		hooks := o.replyHooks(instance, StepTimedOut{}.ReplyName(), msg.ReplyOutcomeFailure)
+
+		results, err := o.handleReply(ctx, instance.getStepContext(), instance.SagaData(), reply)
+		if err != nil {
+			logger.Error("saga reply handler returned an error", log.Error(err))
+			return o.processFailure(ctx, instance, err, hooks, received)
+		}
+
+		err = o.processResults(ctx, instance, results, hooks, received)
+		if err != nil {
+			logger.Error("error while processing results", log.Error(err))
+			return err
+		}
+
+		return nil
+	})
+}
+
+// retryOnConflict runs fn again with the instance reloaded from the store when another process has updated the
+// instance first
+func (o *Orchestrator) retryOnConflict(ctx context.Context, instance *Instance, logger log.Logger, fn func(*Instance) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(instance)
+		if !errors.Is(err, ErrInstanceConflict) || attempt > o.conflictRetries {
+			return err
+		}
+
+		logger.Debug("saga instance was updated by another process", log.Int("Attempt", attempt))
+
+		sagaID := instance.sagaID
+
+		instance, err = o.instanceStore.Find(ctx, o.definition.SagaName(), sagaID)
+		if err != nil {
+			logger.Error("failed to locate saga instance data", log.Error(err))
+			return err
+		}
+
+		if instance == nil {
+			return fmt.Errorf("%w: %s", ErrInstanceNotFound, sagaID)
+		}
+	}
+}
+
+// updateInstance saves the instance and advances it to the version that has been stored
+func (o *Orchestrator) updateInstance(ctx context.Context, instance *Instance) error {
+	err := o.instanceStore.Update(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	instance.version++
+
 	return nil
 }
 
+// scheduleTimeout checks the deadline of the current step once it has passed; deadlines that are missed because the
+// process stopped are found by SweepTimeouts
+func (o *Orchestrator) scheduleTimeout(instance *Instance, timeout time.Duration) {
+	sagaID := instance.sagaID
+	stepID := instance.stepID
+
+	o.clock.AfterFunc(timeout, func() {
+		err := o.checkTimeout(context.Background(), sagaID, stepID)
+		if err != nil {
+			o.logger.Error("error handling saga step timeout", log.String("SagaID", sagaID), log.Error(err))
+		}
+	})
+}
+
 func (o *Orchestrator) replyMessageInfo(message msg.Message) (string, string, string, error) {
 	var err error
 	var replyName, sagaID, sagaName string
@@ -160,7 +598,11 @@ func (o *Orchestrator) replyMessageInfo(message msg.Message) (string, string, st
 	return replyName, sagaID, sagaName, nil
 }
 
-func (o *Orchestrator) processResults(ctx context.Context, instance *Instance, results *stepResults) error {
+// processResults saves and sends each step of the results until the saga waits on a reply or event
+//
+// The received hooks and records, such as those of the reply that produced the results, are fired and recorded with
+// the first saved transition
+func (o *Orchestrator) processResults(ctx context.Context, instance *Instance, results *stepResults, received []HookInfo, records ...HistoryRecord) error {
 	var err error
 
 	logger := o.logger.Sub(
@@ -168,8 +610,22 @@ func (o *Orchestrator) processResults(ctx context.Context, instance *Instance, r
 		log.String("SagaID", instance.sagaID),
 	)
 
+	// hooks are held until the transition has been saved so that they are not fired for transitions that lose a
+	// conflict with another process
+	pending := received
+	var hookErr error
+
 	for {
 		if results.failure != nil {
+			info := o.hookInfo(StepExecuting, instance)
+			info.Step = results.updatedStepContext.step
+			info.Compensating = results.updatedStepContext.compensating
+			pending = append(pending, info)
+
+			info.Hook = StepFailed
+			info.Err = results.failure
+			pending = append(pending, info)
+
 			logger.Trace("handling local failure result")
 			results, err = o.handleReply(ctx, results.updatedStepContext, results.updatedSagaData, msg.WithFailure())
 			if err != nil {
@@ -177,29 +633,94 @@ func (o *Orchestrator) processResults(ctx context.Context, instance *Instance, r
 				return err
 			}
 		} else {
-			for _, command := range results.commands {
-				err = o.publisher.PublishCommand(ctx, o.definition.ReplyChannel(), command, WithSagaInfo(instance))
-				if err != nil {
-					return err
+			// steps waiting on more parallel branch replies keep the step id and deadline
+			if !results.waiting {
+				instance.stepID = ""
+				instance.deadline = time.Time{}
+				instance.correlationKey = results.correlationKey
+
+				if len(results.commands) > 0 || results.correlationKey != "" {
+					instance.stepID = uuid.New().String()
+					if results.timeout > 0 {
+						instance.deadline = o.clock.Now().Add(results.delay + results.timeout)
+					}
 				}
 			}
 
+			wasCompensating := instance.compensating
+
 			instance.updateStepContext(results.updatedStepContext)
 
 			if results.updatedSagaData != nil {
 				instance.sagaData = results.updatedSagaData
 			}
 
-			if results.updatedStepContext.ended {
-				o.processEnd(instance)
+			if instance.compensating && !wasCompensating {
+				pending = append(pending, o.hookInfo(CompensationStarted, instance))
+			}
+
+			if !results.waiting && !instance.endState {
+				pending = append(pending, o.hookInfo(StepExecuting, instance))
 			}
 
-			err = o.instanceStore.Update(ctx, instance)
+			instance.updatedAt = o.clock.Now()
+
+			// the instance is saved first so that nothing is sent when another process has updated it
+			err = o.updateInstance(ctx, instance)
 			if err != nil {
 				logger.Error("error saving saga instance", log.Error(err))
 				return err
 			}
 
+			if instance.compensating && !wasCompensating {
+				records = append(records, HistoryRecord{Type: HistoryCompensationStarted})
+			}
+
+			if !results.waiting && !instance.endState {
+				var detail string
+				if instance.retries > 0 {
+					detail = "retry " + strconv.Itoa(instance.retries)
+				}
+				records = append(records, HistoryRecord{Type: HistoryStepStarted, Detail: detail})
+			}
+
+			if results.delay > 0 && len(results.commands) > 0 && !o.scheduledDelivery {
+				logger.Trace("delaying saga step commands", log.Duration("Delay", results.delay))
+				o.delayCommands(ctx, instance, results)
+			} else {
+				var sent []HistoryRecord
+				sent, err = o.sendCommands(ctx, instance, results)
+				records = append(records, sent...)
+				if err != nil {
+					logger.Error("error sending saga step commands", log.Error(err))
+					o.record(ctx, instance, records...)
+					return o.processFailure(ctx, instance, fmt.Errorf("%w: %s", ErrCommandPublishFailed, err), pending)
+				}
+			}
+
+			o.record(ctx, instance, records...)
+			records = nil
+
+			// the saga keeps going when a hook fails after the transition has been saved; the error is returned at the end
+			for _, info := range pending {
+				if err = o.fireHook(ctx, info); err != nil && hookErr == nil {
+					hookErr = err
+				}
+			}
+			pending = nil
+
+			if results.updatedStepContext.ended {
+				err = o.processEnd(ctx, instance)
+				if err != nil {
+					return err
+				}
+			}
+
+			if !results.waiting && !instance.deadline.IsZero() {
+				logger.Trace("scheduling saga step timeout", log.Duration("Timeout", results.timeout))
+				o.scheduleTimeout(instance, results.delay+results.timeout)
+			}
+
 			if !results.local {
 				logger.Trace("exiting step loop")
 				break
@@ -215,23 +736,265 @@ func (o *Orchestrator) processResults(ctx context.Context, instance *Instance, r
 		}
 	}
 
-	return nil
+	return hookErr
+}
+
+// sendCommands publishes the commands of the step and returns the history records of the commands that were sent
+//
+// Commands of a delayed step are sent with msg.WithDeliverAt; delayCommands is used instead when scheduled delivery
+// has been disabled
+func (o *Orchestrator) sendCommands(ctx context.Context, instance *Instance, results *stepResults) ([]HistoryRecord, error) {
+	var records []HistoryRecord
+
+	msgOptions := []msg.MessageOption{WithSagaInfo(instance)}
+	if results.delay > 0 {
+		msgOptions = append(msgOptions, msg.WithDeliverAt(o.clock.Now().Add(results.delay)))
+	}
+
+	for i, command := range results.commands {
+		var branch string
+		if results.commandBranches != nil {
+			branch = strconv.Itoa(results.commandBranches[i])
+		}
+
+		messageID := uuid.New().String()
+
+		cmdOptions := append(msgOptions[:len(msgOptions):len(msgOptions)], msg.WithMessageID(messageID))
+		if branch != "" {
+			cmdOptions = append(cmdOptions, msg.WithHeaders(map[string]string{
+				MessageCommandSagaBranch: branch,
+			}))
+		}
+
+		err := o.publisher.PublishCommand(ctx, o.definition.ReplyChannel(), command, cmdOptions...)
+		if err != nil {
+			return records, err
+		}
+
+		records = append(records, HistoryRecord{
+			Type:      HistoryCommandSent,
+			Name:      command.CommandName(),
+			MessageID: messageID,
+			Detail:    branchDetail(branch),
+		})
+	}
+
+	return records, nil
+}
+
+// delayCommands holds the commands of a delayed step in a Clock timer and sends them once the delay has passed
+//
+// The commands are not sent when the instance has moved on to a different step in the meantime. Like step timeouts,
+// the timer is lost when the process stops; steps with a timeout are failed by SweepTimeouts when that happens
+func (o *Orchestrator) delayCommands(ctx context.Context, instance *Instance, results *stepResults) {
+	sagaID := instance.sagaID
+	stepID := instance.stepID
+
+	// the timer outlives the current call; only the request context is carried over
+	ctx = core.SetRequestContext(context.Background(), core.GetRequestID(ctx), core.GetCorrelationID(ctx), core.GetCausationID(ctx))
+
+	o.clock.AfterFunc(results.delay, func() {
+		logger := o.logger.Sub(
+			log.String("SagaName", o.definition.SagaName()),
+			log.String("SagaID", sagaID),
+		)
+
+		instance, err := o.instanceStore.Find(ctx, o.definition.SagaName(), sagaID)
+		if err != nil {
+			logger.Error("failed to locate saga instance data", log.Error(err))
+			return
+		}
+
+		if instance == nil || instance.stepID != stepID {
+			logger.Debug("saga step changed before its delayed commands were sent")
+			return
+		}
+
+		delayed := *results
+		delayed.delay = 0
+
+		records, err := o.sendCommands(ctx, instance, &delayed)
+		o.record(ctx, instance, records...)
+		if err != nil {
+			logger.Error("error sending delayed saga step commands", log.Error(err))
+			_ = o.processFailure(ctx, instance, fmt.Errorf("%w: %s", ErrCommandPublishFailed, err), nil)
+		}
+	})
 }
 
-func (o *Orchestrator) processEnd(instance *Instance) {
+// processFailure records instances that cannot continue because compensation has failed or the commands of a step
+// could not be sent
+//
+// The received hooks and records are fired and recorded, followed by the SagaFailed hook, once the instance has been
+// saved. The error is returned unless the instance could not be saved or a hook has returned an error
+func (o *Orchestrator) processFailure(ctx context.Context, instance *Instance, err error, received []HookInfo, records ...HistoryRecord) error {
+	if !errors.Is(err, ErrCompensationFailed) && !errors.Is(err, ErrCommandPublishFailed) {
+		return err
+	}
+
 	logger := o.logger.Sub(
 		log.String("SagaName", o.definition.SagaName()),
 		log.String("SagaID", instance.sagaID),
 	)
 
+	instance.failed = true
+	instance.deadline = time.Time{}
+	instance.updatedAt = o.clock.Now()
+
+	updateErr := o.updateInstance(ctx, instance)
+	if updateErr != nil {
+		logger.Error("error saving saga instance", log.Error(updateErr))
+		return updateErr
+	}
+
+	o.active.Add(-1, metrics.Labels{"saga": o.definition.SagaName()})
+
+	o.record(ctx, instance, append(records, HistoryRecord{Type: HistorySagaFailed, Detail: err.Error()})...)
+
+	info := o.hookInfo(SagaFailed, instance)
+	info.Err = err
+
+	var hookErr error
+	for _, hook := range append(received, info) {
+		if fireErr := o.fireHook(ctx, hook); fireErr != nil && hookErr == nil {
+			hookErr = fireErr
+		}
+	}
+
+	logger.Trace("saga has failed")
+
+	if hookErr != nil {
+		return hookErr
+	}
+
+	return err
+}
+
+// processEnd runs the hook for the end of the saga; The instance has already been saved when the hook returns an error
+func (o *Orchestrator) processEnd(ctx context.Context, instance *Instance) error {
+	logger := o.logger.Sub(
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("SagaID", instance.sagaID),
+	)
+
+	labels := metrics.Labels{"saga": o.definition.SagaName()}
+	o.active.Add(-1, labels)
+
+	hook := SagaCompleted
 	if instance.compensating {
-		logger.Trace("executing saga compensated hook")
-		o.definition.OnHook(SagaCompensated, instance)
+		hook = SagaCompensated
+		o.compensated.Inc(labels)
 	} else {
-		logger.Trace("executing saga completed hook")
-		o.definition.OnHook(SagaCompleted, instance)
+		o.completed.Inc(labels)
 	}
+
+	logger.Trace("executing saga end hook", log.String("Hook", hook.String()))
+	err := o.fireHook(ctx, o.hookInfo(hook, instance))
+	o.record(ctx, instance, HistoryRecord{Type: HistoryHookFired, Name: hook.String()})
+
 	logger.Trace("saga has finished all steps")
+
+	return err
+}
+
+func (o *Orchestrator) hookInfo(hook LifecycleHook, instance *Instance) HookInfo {
+	return HookInfo{
+		Hook:         hook,
+		Instance:     instance,
+		Step:         instance.currentStep,
+		Compensating: instance.compensating,
+	}
+}
+
+// fireHook calls the definition hook and returns the hook error when hook errors abort processing
+//
+// Definitions that do not implement HookDefinition only receive the SagaStarting, SagaCompleted, and SagaCompensated
+// hooks
+func (o *Orchestrator) fireHook(ctx context.Context, info HookInfo) error {
+	definition, ok := o.definition.(HookDefinition)
+	if !ok {
+		switch info.Hook {
+		case SagaStarting, SagaCompleted, SagaCompensated:
+			o.definition.OnHook(info.Hook, info.Instance)
+		}
+		return nil
+	}
+
+	err := definition.OnLifecycleHook(ctx, info)
+	if err == nil {
+		return nil
+	}
+
+	o.logger.Error("saga lifecycle hook returned an error",
+		log.String("SagaName", o.definition.SagaName()),
+		log.String("SagaID", info.Instance.sagaID),
+		log.String("Hook", info.Hook.String()),
+		log.Error(err),
+	)
+
+	if !o.abortOnHookErr {
+		return nil
+	}
+
+	return err
+}
+
+// replyHooks returns the ReplyReceived hook, and the StepFailed hook for failure replies to steps that are not
+// compensating
+func (o *Orchestrator) replyHooks(instance *Instance, replyName, outcome string) []HookInfo {
+	info := o.hookInfo(ReplyReceived, instance)
+	info.ReplyName = replyName
+	info.Outcome = outcome
+
+	hooks := []HookInfo{info}
+	if outcome != msg.ReplyOutcomeFailure || instance.compensating {
+		return hooks
+	}
+
+	info.Hook = StepFailed
+
+	return append(hooks, info)
+}
+
+// record adds the transitions of the instance to the HistoryStore
+//
+// History is kept for support and an error recording it does not stop the saga
+func (o *Orchestrator) record(ctx context.Context, instance *Instance, records ...HistoryRecord) {
+	if o.history == nil || len(records) == 0 {
+		return
+	}
+
+	at := o.clock.Now()
+	for i := range records {
+		// records that were stamped when they were received keep the step they were received at
+		if records[i].SagaID == "" {
+			records[i] = stampRecord(instance, records[i])
+		}
+		records[i].At = at
+	}
+
+	err := o.history.Record(ctx, records...)
+	if err != nil {
+		o.logger.Error("error recording saga history", log.String("SagaID", instance.sagaID), log.Error(err))
+	}
+}
+
+// stampRecord sets the instance and the step of the instance on the record
+func stampRecord(instance *Instance, record HistoryRecord) HistoryRecord {
+	record.SagaName = instance.sagaName
+	record.SagaID = instance.sagaID
+	record.Step = instance.currentStep
+	record.Compensating = instance.compensating
+
+	return record
+}
+
+func branchDetail(branch string) string {
+	if branch == "" {
+		return ""
+	}
+
+	return "branch " + branch
 }
 
 func (o *Orchestrator) handleReply(ctx context.Context, stepCtx stepContext, sagaData core.SagaData, message msg.Reply) (*stepResults, error) {
@@ -249,6 +1012,10 @@ func (o *Orchestrator) handleReply(ctx context.Context, stepCtx stepContext, sag
 	}
 	step := o.definition.Steps()[stepCtx.step]
 
+	if parallel, ok := step.(ParallelStep); ok {
+		return o.handleBranchReply(ctx, parallel, stepCtx, sagaData, message, logger)
+	}
+
 	// handle specific replies
 	if handler := step.getReplyHandler(replyName, stepCtx.compensating); handler != nil {
 		logger.Trace("saga reply handler found")
@@ -273,16 +1040,155 @@ func (o *Orchestrator) handleReply(ctx context.Context, stepCtx stepContext, sag
 	case success:
 		logger.Trace("advancing to next step")
 		return o.executeNextStep(ctx, stepCtx, sagaData), nil
+	case o.shouldRetry(ctx, step, stepCtx, replyName, sagaData):
+		policy := step.getRetryPolicy(stepCtx.compensating)
+		logger.Trace("retrying step", log.Int("Step", stepCtx.step), log.Int("Retries", stepCtx.retries))
+		return o.retryStep(ctx, step, stepCtx, sagaData, policy.interval(stepCtx.retries)), nil
 	case stepCtx.compensating:
 		// we're already failing, we can't fail any more
 		logger.Error("received a failure outcome while compensating", log.Int("Step", stepCtx.step))
-		return nil, fmt.Errorf("received failure outcome while compensating")
+		return nil, ErrCompensationFailed
 	default:
 		logger.Trace("compensating to previous step")
 		return o.executeNextStep(ctx, stepCtx.compensate(), sagaData), nil
 	}
 }
 
+func (o *Orchestrator) handleBranchReply(ctx context.Context, step ParallelStep, stepCtx stepContext, sagaData core.SagaData, message msg.Reply, logger log.Logger) (*stepResults, error) {
+	replyName := message.Reply().ReplyName()
+
+	outcome, err := message.Headers().GetRequired(msg.MessageReplyOutcome)
+	if err != nil {
+		logger.Error("error reading reply outcome", log.Error(err))
+		return nil, err
+	}
+
+	success := outcome == msg.ReplyOutcomeSuccess
+
+	branches := make(map[int]BranchStatus, len(stepCtx.branches))
+	for branch, status := range stepCtx.branches {
+		branches[branch] = status
+	}
+
+	var replied []int
+	if value := message.Headers().Get(MessageReplySagaBranch); value != "" {
+		branch, err := strconv.Atoi(value)
+		if err != nil || branch < 0 || branch >= len(step.branches) {
+			logger.Error("invalid saga branch", log.String("Branch", value))
+			return nil, fmt.Errorf("invalid saga branch: %s", value)
+		}
+		replied = []int{branch}
+	} else {
+		// replies without a branch, such as timeouts, apply to every branch that is still waiting
+		for branch, status := range branches {
+			if status == BranchPending || status == BranchCompensating {
+				replied = append(replied, branch)
+			}
+		}
+		sort.Ints(replied)
+	}
+
+	for _, branch := range replied {
+		status := branches[branch]
+		if status != BranchPending && status != BranchCompensating {
+			logger.Warn("ignoring reply for a branch that is not waiting", log.Int("Branch", branch))
+			continue
+		}
+
+		compensating := status == BranchCompensating
+
+		if handler := step.branches[branch].getReplyHandler(replyName, compensating); handler != nil {
+			logger.Trace("saga reply handler found", log.Int("Branch", branch))
+			err = handler(ctx, sagaData, message.Reply())
+			if err != nil {
+				logger.Error("saga reply handler returned an error", log.Error(err))
+				return nil, err
+			}
+		}
+
+		switch {
+		case success && compensating:
+			branches[branch] = BranchCompensated
+		case success:
+			branches[branch] = BranchSucceeded
+		case compensating:
+			// we're already failing, we can't fail any more
+			logger.Error("received a failure outcome while compensating", log.Int("Step", stepCtx.step), log.Int("Branch", branch))
+			return nil, ErrCompensationFailed
+		default:
+			branches[branch] = BranchFailed
+		}
+	}
+
+	stepCtx.branches = branches
+
+	failed := false
+	for _, status := range branches {
+		switch status {
+		case BranchPending, BranchCompensating:
+			logger.Trace("waiting on parallel branches")
+			return &stepResults{
+				updatedSagaData:    sagaData,
+				updatedStepContext: stepCtx,
+				waiting:            true,
+			}, nil
+		case BranchFailed:
+			failed = true
+		}
+	}
+
+	switch {
+	case stepCtx.compensating:
+		logger.Trace("compensating to previous step")
+		return o.executeNextStep(ctx, stepCtx, sagaData), nil
+	case failed:
+		logger.Trace("compensating succeeded parallel branches")
+		compensateCtx := stepCtx.compensate()
+		compensateCtx.branches = branches
+
+		results := &stepResults{
+			updatedSagaData:    sagaData,
+			updatedStepContext: compensateCtx,
+		}
+
+		step.executeBranches(ctx, sagaData, isCompensating, func(branch int) bool {
+			return branches[branch] == BranchSucceeded
+		})(results)
+
+		if len(results.commands) == 0 {
+			logger.Trace("compensating to previous step")
+			return o.executeNextStep(ctx, stepCtx.compensate(), sagaData), nil
+		}
+
+		return results, nil
+	default:
+		logger.Trace("advancing to next step")
+		return o.executeNextStep(ctx, stepCtx, sagaData), nil
+	}
+}
+
+func (o *Orchestrator) shouldRetry(ctx context.Context, step Step, stepCtx stepContext, replyName string, sagaData core.SagaData) bool {
+	policy := step.getRetryPolicy(stepCtx.compensating)
+	if policy == nil || !policy.shouldRetry(replyName, stepCtx.retries) {
+		return false
+	}
+
+	// the step may no longer have an action to retry
+	return step.hasInvocableAction(ctx, sagaData, stepCtx.compensating)
+}
+
+func (o *Orchestrator) retryStep(ctx context.Context, step Step, stepCtx stepContext, sagaData core.SagaData, delay time.Duration) *stepResults {
+	results := &stepResults{
+		updatedSagaData:    sagaData,
+		updatedStepContext: stepCtx.retry(),
+		delay:              delay,
+	}
+
+	step.execute(ctx, sagaData, stepCtx.compensating)(results)
+
+	return results
+}
+
 func (o *Orchestrator) executeNextStep(ctx context.Context, stepCtx stepContext, sagaData core.SagaData) *stepResults {
 	var stepDelta = 1
 	var direction = 1
@@ -2,6 +2,7 @@ package saga
 
 import (
 	"github.com/stackus/edat/log"
+	"github.com/stackus/edat/metrics"
 )
 
 // OrchestratorOption options for Orchestrator
@@ -13,3 +14,56 @@ func WithOrchestratorLogger(logger log.Logger) OrchestratorOption {
 		o.logger = logger
 	}
 }
+
+// WithOrchestratorMetrics is an option to set the metrics.Registry of the Orchestrator
+func WithOrchestratorMetrics(registry metrics.Registry) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.registry = registry
+	}
+}
+
+// WithOrchestratorClock is an option to set the Clock the Orchestrator uses for step deadlines
+func WithOrchestratorClock(clock Clock) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.clock = clock
+	}
+}
+
+// WithOrchestratorConflictRetries is an option to set how many times a message is processed again when another
+// process has updated the saga instance first
+func WithOrchestratorConflictRetries(retries int) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.conflictRetries = retries
+	}
+}
+
+// WithOrchestratorHistory is an option to set the HistoryStore the transitions of each saga instance are recorded to
+func WithOrchestratorHistory(history HistoryStore) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.history = history
+	}
+}
+
+// WithOrchestratorAbortOnHookError is an option to stop processing a saga when a HookDefinition hook returns an error
+//
+// Hook errors are returned to the caller. Errors from the SagaStarting hook, and from the SagaFailed hook of
+// MarkFailed, stop the saga from being saved or being marked failed. Every other hook is called once the instance has
+// been saved and the commands of the step sent; their errors are returned after the saga has been processed
+func WithOrchestratorAbortOnHookError(abort bool) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.abortOnHookErr = abort
+	}
+}
+
+// WithOrchestratorScheduledDelivery is an option to choose how the commands of delayed steps, such as retries, are
+// held until they are due
+//
+// By default the commands are sent right away with msg.WithDeliverAt. The publisher must use the msg.Scheduler
+// middleware for the delays to be honored; commands held by the scheduler are stored and are not lost when the
+// process stops. Disabling scheduled delivery holds the commands in a Clock timer instead, which is lost when the
+// process stops
+func WithOrchestratorScheduledDelivery(scheduled bool) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.scheduledDelivery = scheduled
+	}
+}
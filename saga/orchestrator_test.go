@@ -0,0 +1,802 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/metrics"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/retry"
+	"github.com/stackus/edat/saga"
+	"github.com/stackus/edat/saga/sagatest"
+)
+
+type (
+	reserveCredit struct{}
+	releaseCredit struct{}
+	approveOrder  struct{}
+)
+
+func (reserveCredit) CommandName() string        { return "saga_test.reserveCredit" }
+func (reserveCredit) DestinationChannel() string { return "customers" }
+func (releaseCredit) CommandName() string        { return "saga_test.releaseCredit" }
+func (releaseCredit) DestinationChannel() string { return "customers" }
+func (approveOrder) CommandName() string         { return "saga_test.approveOrder" }
+func (approveOrder) DestinationChannel() string  { return "orders" }
+
+type orderData struct {
+	TimedOut bool
+	Replies  int
+}
+
+func (orderData) SagaDataName() string { return "saga_test.orderData" }
+
+type testDefinition struct {
+	steps []saga.Step
+	hooks []saga.LifecycleHook
+	mu    sync.Mutex
+}
+
+func (d *testDefinition) SagaName() string     { return "saga_test.OrderSaga" }
+func (d *testDefinition) ReplyChannel() string { return "saga_test.OrderSaga.reply" }
+func (d *testDefinition) Steps() []saga.Step   { return d.steps }
+func (d *testDefinition) OnHook(hook saga.LifecycleHook, _ *saga.Instance) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hooks = append(d.hooks, hook)
+}
+
+type sentCommand struct {
+	command core.Command
+	message msg.Message
+}
+
+type recordingPublisher struct {
+	commands []sentCommand
+	err      error
+	mu       sync.Mutex
+}
+
+func (p *recordingPublisher) PublishCommand(_ context.Context, _ string, command core.Command, options ...msg.MessageOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.commands = append(p.commands, sentCommand{command: command, message: msg.NewMessage(nil, options...)})
+	return nil
+}
+
+func (p *recordingPublisher) sent() []sentCommand {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]sentCommand{}, p.commands...)
+}
+
+// replyTo builds the reply message a participant would send for the command
+func replyTo(command msg.Message, reply msg.Reply) msg.Message {
+	headers := map[string]string{}
+	for key, value := range command.Headers() {
+		if strings.HasPrefix(key, msg.MessageCommandPrefix) {
+			headers[msg.MessageReplyPrefix+key[len(msg.MessageCommandPrefix):]] = value
+		}
+	}
+	for key, value := range reply.Headers() {
+		headers[key] = value
+	}
+	return msg.NewMessage([]byte(`{}`), msg.WithHeaders(headers))
+}
+
+func TestOrchestrator_StepTimeout(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }, saga.WithRemoteStepTimeout(time.Minute)).
+				HandleActionReply(saga.StepTimedOut{}, func(_ context.Context, data core.SagaData, _ core.Reply) error {
+					data.(*orderData).TimedOut = true
+					return nil
+				}),
+		},
+	}
+
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := clock.Pending(); got != 0 {
+		t.Errorf("steps without a timeout scheduled %d timeouts", got)
+	}
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	sent := publisher.sent()
+	if len(sent) != 2 || sent[1].command.CommandName() != (approveOrder{}).CommandName() {
+		t.Fatalf("commands sent = %v, want approveOrder", sent)
+	}
+	approve := sent[1].message
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if want := clock.Now().Add(time.Minute); !found.Deadline().Equal(want) {
+		t.Errorf("Deadline() = %v, want %v", found.Deadline(), want)
+	}
+	if got := approve.Headers().Get(saga.MessageCommandSagaStepID); got == "" || got != found.StepID() {
+		t.Errorf("step id header = %v, want %v", got, found.StepID())
+	}
+
+	// the deadline has not yet passed
+	clock.Advance(59 * time.Second)
+	if err = orchestrator.CheckTimeout(ctx, instance.SagaID()); err != nil {
+		t.Fatalf("CheckTimeout() error = %v", err)
+	}
+	if len(publisher.sent()) != 2 {
+		t.Fatalf("step timed out before its deadline")
+	}
+
+	clock.Advance(time.Second)
+
+	sent = publisher.sent()
+	if len(sent) != 3 || sent[2].command.CommandName() != (releaseCredit{}).CommandName() {
+		t.Fatalf("commands sent = %v, want releaseCredit", sent)
+	}
+
+	found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if !found.Compensating() || !found.SagaData().(*orderData).TimedOut {
+		t.Errorf("instance is not compensating after timeout: %+v", found)
+	}
+
+	// the late reply from the participant is ignored
+	err = orchestrator.ReceiveMessage(ctx, replyTo(approve, msg.WithSuccess()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if len(publisher.sent()) != 3 {
+		t.Fatalf("late reply was not ignored")
+	}
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(sent[2].message, msg.WithSuccess()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if !found.EndState() || !found.Deadline().IsZero() {
+		t.Errorf("instance has not ended: %+v", found)
+	}
+	if want := []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompensated}; len(definition.hooks) != 2 || definition.hooks[1] != want[1] {
+		t.Errorf("hooks = %v, want %v", definition.hooks, want)
+	}
+
+	// replies for an ended saga are ignored
+	err = orchestrator.ReceiveMessage(ctx, replyTo(sent[2].message, msg.WithSuccess()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if len(definition.hooks) != 2 {
+		t.Errorf("hooks = %v, want no more hooks", definition.hooks)
+	}
+}
+
+func TestOrchestrator_StepReplyBeforeTimeout(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }, saga.WithRemoteStepTimeout(time.Minute)),
+		},
+	}
+
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	clock.Advance(time.Hour)
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if !found.EndState() || found.Compensating() {
+		t.Errorf("instance did not complete: %+v", found)
+	}
+	if want := []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompleted}; len(definition.hooks) != 2 || definition.hooks[1] != want[1] {
+		t.Errorf("hooks = %v, want %v", definition.hooks, want)
+	}
+}
+
+func TestOrchestrator_CheckTimeouts(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }, saga.WithRemoteStepTimeout(time.Minute)).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+
+	ctx := context.Background()
+	instance, err := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(sagatest.NewClock(start))).
+		Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// the process restarts; the new orchestrator has no timer waiting on the deadline
+	clock := sagatest.NewClock(start)
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+	clock.Advance(59 * time.Second)
+	if err = orchestrator.CheckTimeouts(ctx); err != nil {
+		t.Fatalf("CheckTimeouts() error = %v", err)
+	}
+	if found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID()); !found.Deadline().After(clock.Now()) || found.EndState() {
+		t.Fatalf("step timed out before its deadline: %+v", found)
+	}
+
+	clock.Advance(2 * time.Second)
+	if err = orchestrator.CheckTimeouts(ctx); err != nil {
+		t.Fatalf("CheckTimeouts() error = %v", err)
+	}
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if !found.EndState() || !found.Compensating() {
+		t.Errorf("instance was not compensated after its deadline passed: %+v", found)
+	}
+
+	unqueryable := saga.NewOrchestrator(definition, struct{ saga.InstanceStore }{store}, publisher)
+	if err = unqueryable.CheckTimeouts(ctx); !errors.Is(err, saga.ErrInstanceStoreNotQueryable) {
+		t.Errorf("CheckTimeouts() error = %v, want %v", err, saga.ErrInstanceStoreNotQueryable)
+	}
+}
+
+func TestOrchestrator_StepRetry(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	type want struct {
+		retries      []int
+		compensating bool
+	}
+	tests := map[string]struct {
+		replies []msg.Reply
+		want    want
+	}{
+		"RetriedSuccess": {
+			replies: []msg.Reply{msg.WithFailure(), msg.WithSuccess()},
+			want:    want{retries: []int{1}, compensating: false},
+		},
+		"AttemptsExceeded": {
+			replies: []msg.Reply{msg.WithFailure(), msg.WithFailure(), msg.WithFailure()},
+			want:    want{retries: []int{1, 2}, compensating: true},
+		},
+		"NotRetryable": {
+			replies: []msg.Reply{msg.WithExpired()},
+			want:    want{retries: nil, compensating: true},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			clock := sagatest.NewClock(now)
+			publisher := &recordingPublisher{}
+			store := inmem.NewSagaInstanceStore()
+
+			policy := saga.NewRetryPolicy(3,
+				saga.WithRetryPolicyBackoff(retry.NewConstantBackoff(retry.WithBackoffInitialInterval(time.Second))),
+				saga.WithRetryPolicyRetryable(saga.RetryReplies(msg.Failure{})),
+			)
+
+			definition := &testDefinition{
+				steps: []saga.Step{
+					saga.NewRemoteStep().
+						Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+						Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+					saga.NewRemoteStep().
+						Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }, saga.WithRemoteStepRetry(policy)),
+				},
+			}
+
+			ctx := context.Background()
+			instance, err := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock)).Start(ctx, &orderData{})
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			err = saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock)).
+				ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+			if err != nil {
+				t.Fatalf("ReceiveMessage() error = %v", err)
+			}
+
+			var retries []int
+			for _, reply := range tt.replies {
+				sent := publisher.sent()
+				last := sent[len(sent)-1]
+
+				// a new orchestrator with the default options is used for each reply to show retries survive a restart
+				orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+				err = orchestrator.ReceiveMessage(ctx, replyTo(last.message, reply))
+				if err != nil {
+					t.Fatalf("ReceiveMessage() error = %v", err)
+				}
+
+				after := publisher.sent()
+				if len(after) == len(sent) {
+					continue
+				}
+				next := after[len(after)-1]
+				if next.command.CommandName() != (approveOrder{}).CommandName() {
+					continue
+				}
+
+				found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+				retries = append(retries, found.Retries())
+
+				if got, want := next.message.Headers().Get(msg.MessageDeliverAt), now.Add(time.Second).Format(time.RFC3339Nano); got != want {
+					t.Errorf("retry %s = %v, want %v", msg.MessageDeliverAt, got, want)
+				}
+			}
+
+			if !reflect.DeepEqual(retries, tt.want.retries) {
+				t.Errorf("Retries() = %v, want %v", retries, tt.want.retries)
+			}
+
+			found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+			if found.Compensating() != tt.want.compensating {
+				t.Errorf("Compensating() = %v, want %v", found.Compensating(), tt.want.compensating)
+			}
+			if tt.want.compensating {
+				sent := publisher.sent()
+				if last := sent[len(sent)-1]; last.command.CommandName() != (releaseCredit{}).CommandName() || found.Retries() != 0 {
+					t.Errorf("last command = %v, retries = %d, want releaseCredit and no retries", last.command.CommandName(), found.Retries())
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_StepRetryDelay(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+
+	policy := saga.NewRetryPolicy(3,
+		saga.WithRetryPolicyBackoff(retry.NewConstantBackoff(retry.WithBackoffInitialInterval(time.Second))),
+	)
+
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }, saga.WithRemoteStepRetry(policy)),
+		},
+	}
+
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock), saga.WithOrchestratorScheduledDelivery(false))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithFailure()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	// the retry is held by the orchestrator until the backoff has passed
+	clock.Advance(999 * time.Millisecond)
+	if got := len(publisher.sent()); got != 1 {
+		t.Fatalf("retry was sent before the backoff had passed")
+	}
+
+	clock.Advance(time.Millisecond)
+	sent := publisher.sent()
+	if len(sent) != 2 {
+		t.Fatalf("retry was not sent after the backoff had passed")
+	}
+	if got := sent[1].message.Headers().Get(msg.MessageDeliverAt); got != "" {
+		t.Errorf("retry %s = %v, want none", msg.MessageDeliverAt, got)
+	}
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if got := sent[1].message.Headers().Get(saga.MessageCommandSagaStepID); got != found.StepID() {
+		t.Errorf("retry step id header = %v, want %v", got, found.StepID())
+	}
+
+	// a retry is not sent when the step has moved on before the backoff has passed
+	err = orchestrator.ReceiveMessage(ctx, replyTo(sent[1].message, msg.WithFailure()))
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+
+	_, err = orchestrator.RetryStep(ctx, instance.SagaID(), "operator", "reason")
+	if err != nil {
+		t.Fatalf("RetryStep() error = %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if got := len(publisher.sent()); got != 3 {
+		t.Errorf("commands sent = %d, want 3", got)
+	}
+}
+
+func TestOrchestrator_CompensationFailed(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	started := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := sagatest.NewClock(started)
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }).
+				Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return releaseCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if instance.State() != saga.InstanceRunning || !instance.CreatedAt().Equal(started) {
+		t.Errorf("State() = %v, CreatedAt() = %v, want running at %v", instance.State(), instance.CreatedAt(), started)
+	}
+
+	replies := []msg.Reply{msg.WithSuccess(), msg.WithFailure()}
+	for _, reply := range replies {
+		clock.Advance(time.Minute)
+		sent := publisher.sent()
+		err = orchestrator.ReceiveMessage(ctx, replyTo(sent[len(sent)-1].message, reply))
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+	}
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceCompensating {
+		t.Errorf("State() = %v, want %v", found.State(), saga.InstanceCompensating)
+	}
+
+	clock.Advance(time.Minute)
+	sent := publisher.sent()
+	err = orchestrator.ReceiveMessage(ctx, replyTo(sent[len(sent)-1].message, msg.WithFailure()))
+	if !errors.Is(err, saga.ErrCompensationFailed) {
+		t.Fatalf("ReceiveMessage() error = %v, want %v", err, saga.ErrCompensationFailed)
+	}
+
+	found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceFailed {
+		t.Errorf("State() = %v, want %v", found.State(), saga.InstanceFailed)
+	}
+	if want := started.Add(3 * time.Minute); !found.UpdatedAt().Equal(want) {
+		t.Errorf("UpdatedAt() = %v, want %v", found.UpdatedAt(), want)
+	}
+
+	// replies for a failed saga are ignored
+	err = orchestrator.ReceiveMessage(ctx, replyTo(sent[len(sent)-1].message, msg.WithFailure()))
+	if err != nil {
+		t.Errorf("ReceiveMessage() error = %v", err)
+	}
+}
+
+func TestOrchestrator_CommandPublishFailed(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	registry := metrics.NewMemoryRegistry()
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorMetrics(registry))
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	publisher.err = errors.New("broker is unavailable")
+
+	err = orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+	if !errors.Is(err, saga.ErrCommandPublishFailed) {
+		t.Fatalf("ReceiveMessage() error = %v, want %v", err, saga.ErrCommandPublishFailed)
+	}
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceFailed || found.CurrentStep() != 1 {
+		t.Fatalf("instance = %v at step %d, want failed at step 1", found.State(), found.CurrentStep())
+	}
+	if active := activeSagas(registry); active != 0 {
+		t.Errorf("active sagas = %v, want 0", active)
+	}
+
+	// the step is retried with the same outcome while the publisher is unavailable
+	_, err = orchestrator.RetryStep(ctx, instance.SagaID(), "operator", "broker outage")
+	if !errors.Is(err, saga.ErrCommandPublishFailed) {
+		t.Fatalf("RetryStep() error = %v, want %v", err, saga.ErrCommandPublishFailed)
+	}
+	if active := activeSagas(registry); active != 0 {
+		t.Errorf("active sagas = %v, want 0", active)
+	}
+
+	publisher.err = nil
+
+	_, err = orchestrator.RetryStep(ctx, instance.SagaID(), "operator", "broker restored")
+	if err != nil {
+		t.Fatalf("RetryStep() error = %v", err)
+	}
+
+	found, _ = store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.State() != saga.InstanceRunning {
+		t.Errorf("State() = %v, want %v", found.State(), saga.InstanceRunning)
+	}
+	if sent := publisher.sent(); len(sent) != 2 || sent[1].command.CommandName() != "saga_test.approveOrder" {
+		t.Errorf("commands sent = %v, want the approveOrder command resent", sent)
+	}
+	if active := activeSagas(registry); active != 1 {
+		t.Errorf("active sagas = %v, want 1", active)
+	}
+}
+
+func TestOrchestrator_ConcurrentReplies(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	const branchCount = 8
+
+	// the reply handlers hold each reply between loading and saving the instance so that the replies overlap, and
+	// count the replies in the saga data; each handler must be given its own copy of the data
+	branches := make([]saga.RemoteStep, 0, branchCount)
+	for i := 0; i < branchCount; i++ {
+		branches = append(branches, bookingStep(strconv.Itoa(i)).
+			HandleActionReply(msg.Success{}, func(_ context.Context, data core.SagaData, _ core.Reply) error {
+				data.(*orderData).Replies++
+				time.Sleep(time.Millisecond)
+				return nil
+			}))
+	}
+
+	publisher := &recordingPublisher{}
+	store := inmem.NewSagaInstanceStore()
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewParallelStep(branches...),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher)
+
+	ctx := context.Background()
+	instance, err := orchestrator.Start(ctx, &orderData{})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	commands := publisher.sent()
+	if len(commands) != branchCount {
+		t.Fatalf("commands sent = %d, want %d", len(commands), branchCount)
+	}
+
+	// every branch replies at once; each reply must see the branch statuses saved by the others
+	start := make(chan struct{})
+	errs := make(chan error, branchCount)
+	var wg sync.WaitGroup
+	for _, command := range commands {
+		wg.Add(1)
+		go func(command msg.Message) {
+			defer wg.Done()
+			<-start
+			errs <- orchestrator.ReceiveMessage(ctx, replyTo(command, msg.WithSuccess()))
+		}(command.message)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("ReceiveMessage() error = %v", err)
+		}
+	}
+
+	var approvals int
+	for _, command := range publisher.sent() {
+		if command.command.CommandName() == "saga_test.approveOrder" {
+			approvals++
+		}
+	}
+	if approvals != 1 {
+		t.Errorf("approveOrder sent %d times, want 1", approvals)
+	}
+
+	found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+	if found.CurrentStep() != 1 {
+		t.Errorf("CurrentStep() = %d, want 1", found.CurrentStep())
+	}
+	// one update when started and one for each branch reply
+	if found.Version() != branchCount+1 {
+		t.Errorf("Version() = %d, want %d", found.Version(), branchCount+1)
+	}
+	// replies processed again after a conflict are only counted in the saved data once
+	if replies := found.SagaData().(*orderData).Replies; replies != branchCount {
+		t.Errorf("Replies = %d, want %d", replies, branchCount)
+	}
+}
+
+func TestOrchestrator_ConflictRetriesExhausted(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	publisher := &recordingPublisher{}
+	store := &conflictingStore{SagaInstanceStore: inmem.NewSagaInstanceStore()}
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+	orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorConflictRetries(2))
+
+	ctx := context.Background()
+	if _, err := orchestrator.Start(ctx, &orderData{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	store.conflicts = true
+
+	err := orchestrator.ReceiveMessage(ctx, replyTo(publisher.sent()[0].message, msg.WithSuccess()))
+
+	var conflict *saga.InstanceConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, saga.ErrInstanceConflict) {
+		t.Fatalf("ReceiveMessage() error = %v, want %v", err, saga.ErrInstanceConflict)
+	}
+	if store.updates != 3 {
+		t.Errorf("updates attempted = %d, want 3", store.updates)
+	}
+	// nothing is sent for a step that could not be saved
+	if sent := publisher.sent(); len(sent) != 1 {
+		t.Errorf("commands sent = %d, want 1", len(sent))
+	}
+}
+
+// conflictingStore rejects every update as stale once conflicts has been set
+type conflictingStore struct {
+	*inmem.SagaInstanceStore
+	conflicts bool
+	updates   int
+}
+
+func (s *conflictingStore) Update(ctx context.Context, instance *saga.Instance) error {
+	if !s.conflicts {
+		return s.SagaInstanceStore.Update(ctx, instance)
+	}
+
+	s.updates++
+
+	return &saga.InstanceConflictError{
+		SagaName:      instance.SagaName(),
+		SagaID:        instance.SagaID(),
+		Version:       instance.Version(),
+		StoredVersion: instance.Version() + 1,
+	}
+}
+
+func TestOrchestrator_StartMetrics(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	errLocal := errors.New("local step failed")
+
+	tests := map[string]struct {
+		step       saga.Step
+		wantErr    error
+		wantActive float64
+	}{
+		"Started": {
+			step: saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return reserveCredit{} }),
+			wantActive: 1,
+		},
+		"LocalStepFailed": {
+			step:       saga.NewLocalStep(func(context.Context, core.SagaData) error { return errLocal }),
+			wantErr:    errLocal,
+			wantActive: 0,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			registry := metrics.NewMemoryRegistry()
+			definition := &testDefinition{steps: []saga.Step{tt.step}}
+			orchestrator := saga.NewOrchestrator(definition, inmem.NewSagaInstanceStore(), &recordingPublisher{},
+				saga.WithOrchestratorMetrics(registry))
+
+			_, err := orchestrator.Start(context.Background(), &orderData{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Start() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if active := activeSagas(registry); active != tt.wantActive {
+				t.Errorf("active sagas = %v, want %v", active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func activeSagas(registry *metrics.MemoryRegistry) float64 {
+	var active float64
+	for _, family := range registry.Gather() {
+		if family.Name != metrics.SagasActive {
+			continue
+		}
+		for _, sample := range family.Samples {
+			active += sample.Value
+		}
+	}
+	return active
+}
@@ -0,0 +1,117 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stackus/edat/core"
+)
+
+// BranchStatus is the progress of a single branch of a ParallelStep
+type BranchStatus string
+
+// ParallelStep branch statuses
+const (
+	BranchPending      BranchStatus = "pending"
+	BranchSucceeded    BranchStatus = "succeeded"
+	BranchFailed       BranchStatus = "failed"
+	BranchCompensating BranchStatus = "compensating"
+	BranchCompensated  BranchStatus = "compensated"
+)
+
+// ParallelStep is used to execute several independent distributed saga actions at the same time
+//
+// The commands of all branches are sent at once and the saga advances when every branch has succeeded. When any
+// branch fails only the branches that succeeded are compensated before the saga continues compensating
+//  saga.NewParallelStep(reserveHotel, reserveCar, reserveFlight)
+type ParallelStep struct {
+	branches []RemoteStep
+}
+
+var _ Step = (*ParallelStep)(nil)
+
+// NewParallelStep constructor for ParallelStep
+//
+// Branches may use timeouts, with the longest timeout of the sent branches used for the whole step. Retry policies
+// are not supported for branches and are ignored; they are reported as an ErrBranchRetryPolicy error by
+// DefinitionBuilder.Build and NewValidatedOrchestrator
+func NewParallelStep(branches ...RemoteStep) ParallelStep {
+	return ParallelStep{
+		branches: branches,
+	}
+}
+
+// branchRetryPolicies returns an ErrBranchRetryPolicy error for each branch that has a retry policy
+func (s ParallelStep) branchRetryPolicies(name string) []error {
+	var errs []error
+	for i, branch := range s.branches {
+		if branch.getRetryPolicy(notCompensating) != nil || branch.getRetryPolicy(isCompensating) != nil {
+			errs = append(errs, fmt.Errorf("%s branch %d: %w", name, i, ErrBranchRetryPolicy))
+		}
+	}
+
+	return errs
+}
+
+func (s ParallelStep) hasInvocableAction(ctx context.Context, sagaData core.SagaData, compensating bool) bool {
+	for _, branch := range s.branches {
+		if branch.hasInvocableAction(ctx, sagaData, compensating) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getReplyHandler is not used; replies are handled by the handlers of each branch
+func (s ParallelStep) getReplyHandler(string, bool) func(context.Context, core.SagaData, core.Reply) error {
+	return nil
+}
+
+func (s ParallelStep) getRetryPolicy(bool) *RetryPolicy {
+	return nil
+}
+
+func (s ParallelStep) execute(ctx context.Context, sagaData core.SagaData, compensating bool) func(results *stepResults) {
+	return s.executeBranches(ctx, sagaData, compensating, func(int) bool { return true })
+}
+
+func (s ParallelStep) executeBranches(ctx context.Context, sagaData core.SagaData, compensating bool, include func(branch int) bool) func(results *stepResults) {
+	status := BranchPending
+	if compensating {
+		status = BranchCompensating
+	}
+
+	branchResults := make([]*stepResults, len(s.branches))
+	for i, branch := range s.branches {
+		if !include(i) || !branch.hasInvocableAction(ctx, sagaData, compensating) {
+			continue
+		}
+
+		branchResults[i] = &stepResults{}
+		branch.execute(ctx, sagaData, compensating)(branchResults[i])
+	}
+
+	return func(results *stepResults) {
+		if results.updatedStepContext.branches == nil {
+			results.updatedStepContext.branches = map[int]BranchStatus{}
+		}
+
+		for i, branchResult := range branchResults {
+			if branchResult == nil || len(branchResult.commands) == 0 {
+				continue
+			}
+
+			for _, command := range branchResult.commands {
+				results.commands = append(results.commands, command)
+				results.commandBranches = append(results.commandBranches, i)
+			}
+
+			if branchResult.timeout > results.timeout {
+				results.timeout = branchResult.timeout
+			}
+
+			results.updatedStepContext.branches[i] = status
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/core/coretest"
+	"github.com/stackus/edat/inmem"
+	"github.com/stackus/edat/msg"
+	"github.com/stackus/edat/saga"
+	"github.com/stackus/edat/saga/sagatest"
+)
+
+type bookingCommand struct{ Name string }
+
+func (c bookingCommand) CommandName() string        { return "saga_test." + c.Name }
+func (c bookingCommand) DestinationChannel() string { return "bookings" }
+
+func bookingStep(name string, options ...saga.RemoteStepActionOption) saga.RemoteStep {
+	return saga.NewRemoteStep().
+		Action(func(context.Context, core.SagaData) msg.DomainCommand { return bookingCommand{"reserve" + name} }, options...).
+		Compensation(func(context.Context, core.SagaData) msg.DomainCommand { return bookingCommand{"cancel" + name} })
+}
+
+func TestParallelStep(t *testing.T) {
+	core.RegisterDefaultMarshaller(coretest.NewTestMarshaller())
+	msg.RegisterTypes()
+
+	type reply struct {
+		command string
+		reply   msg.Reply
+	}
+	type want struct {
+		sent     []string
+		hooks    []saga.LifecycleHook
+		branches map[int]saga.BranchStatus
+	}
+	tests := map[string]struct {
+		replies []reply
+		advance time.Duration
+		want    want
+	}{
+		"AllSucceed": {
+			replies: []reply{
+				{"saga_test.reserveFlight", msg.WithSuccess()},
+				{"saga_test.reserveHotel", msg.WithSuccess()},
+				{"saga_test.reserveCar", msg.WithSuccess()},
+				{"saga_test.approveOrder", msg.WithSuccess()},
+			},
+			want: want{
+				sent:  []string{"saga_test.approveOrder"},
+				hooks: []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompleted},
+			},
+		},
+		"Waiting": {
+			replies: []reply{
+				{"saga_test.reserveHotel", msg.WithSuccess()},
+				{"saga_test.reserveCar", msg.WithFailure()},
+				// duplicate replies are ignored
+				{"saga_test.reserveCar", msg.WithSuccess()},
+			},
+			want: want{
+				hooks: []saga.LifecycleHook{saga.SagaStarting},
+				branches: map[int]saga.BranchStatus{
+					0: saga.BranchSucceeded,
+					1: saga.BranchFailed,
+					2: saga.BranchPending,
+				},
+			},
+		},
+		"OneFails": {
+			replies: []reply{
+				{"saga_test.reserveFlight", msg.WithFailure()},
+				{"saga_test.reserveHotel", msg.WithSuccess()},
+				{"saga_test.reserveCar", msg.WithSuccess()},
+				{"saga_test.cancelCar", msg.WithSuccess()},
+				{"saga_test.cancelHotel", msg.WithSuccess()},
+			},
+			want: want{
+				sent:  []string{"saga_test.cancelHotel", "saga_test.cancelCar"},
+				hooks: []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompensated},
+			},
+		},
+		"TwoFail": {
+			replies: []reply{
+				{"saga_test.reserveCar", msg.WithFailure()},
+				{"saga_test.reserveHotel", msg.WithSuccess()},
+				{"saga_test.reserveFlight", msg.WithFailure()},
+				{"saga_test.cancelHotel", msg.WithSuccess()},
+			},
+			want: want{
+				sent:  []string{"saga_test.cancelHotel"},
+				hooks: []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompensated},
+			},
+		},
+		"AllFail": {
+			replies: []reply{
+				{"saga_test.reserveCar", msg.WithFailure()},
+				{"saga_test.reserveHotel", msg.WithFailure()},
+				{"saga_test.reserveFlight", msg.WithFailure()},
+			},
+			want: want{
+				hooks: []saga.LifecycleHook{saga.SagaStarting, saga.SagaCompensated},
+			},
+		},
+		"TimedOut": {
+			replies: []reply{
+				{"saga_test.reserveHotel", msg.WithSuccess()},
+			},
+			advance: time.Minute,
+			want: want{
+				sent:  []string{"saga_test.cancelHotel"},
+				hooks: []saga.LifecycleHook{saga.SagaStarting},
+				branches: map[int]saga.BranchStatus{
+					0: saga.BranchCompensating,
+					1: saga.BranchFailed,
+					2: saga.BranchFailed,
+				},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := sagatest.NewClock(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+			publisher := &recordingPublisher{}
+			store := inmem.NewSagaInstanceStore()
+
+			definition := &testDefinition{
+				steps: []saga.Step{
+					saga.NewParallelStep(
+						bookingStep("Hotel"),
+						bookingStep("Car", saga.WithRemoteStepTimeout(time.Minute)),
+						saga.NewRemoteStep().
+							Action(func(context.Context, core.SagaData) msg.DomainCommand { return bookingCommand{"reserveFlight"} }),
+					),
+					saga.NewRemoteStep().
+						Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+				},
+			}
+
+			orchestrator := saga.NewOrchestrator(definition, store, publisher, saga.WithOrchestratorClock(clock))
+
+			ctx := context.Background()
+			instance, err := orchestrator.Start(ctx, &orderData{})
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			started := publisher.sent()
+			var names []string
+			for _, command := range started {
+				names = append(names, command.command.CommandName())
+			}
+			sort.Strings(names)
+			if want := []string{"saga_test.reserveCar", "saga_test.reserveFlight", "saga_test.reserveHotel"}; !reflect.DeepEqual(names, want) {
+				t.Fatalf("commands sent = %v, want %v", names, want)
+			}
+
+			for _, r := range tt.replies {
+				var command msg.Message
+				for _, sent := range publisher.sent() {
+					if sent.command.CommandName() == r.command {
+						command = sent.message
+					}
+				}
+				if command == nil {
+					t.Fatalf("command %s was not sent", r.command)
+				}
+
+				err = orchestrator.ReceiveMessage(ctx, replyTo(command, r.reply))
+				if err != nil {
+					t.Fatalf("ReceiveMessage() error = %v", err)
+				}
+			}
+
+			clock.Advance(tt.advance)
+
+			var sent []string
+			for _, command := range publisher.sent()[len(started):] {
+				sent = append(sent, command.command.CommandName())
+			}
+			sort.Strings(sent)
+			wantSent := append([]string{}, tt.want.sent...)
+			sort.Strings(wantSent)
+			if len(sent) != 0 || len(wantSent) != 0 {
+				if !reflect.DeepEqual(sent, wantSent) {
+					t.Errorf("commands sent = %v, want %v", sent, wantSent)
+				}
+			}
+
+			if !reflect.DeepEqual(definition.hooks, tt.want.hooks) {
+				t.Errorf("hooks = %v, want %v", definition.hooks, tt.want.hooks)
+			}
+
+			if tt.want.branches != nil {
+				found, _ := store.Find(ctx, definition.SagaName(), instance.SagaID())
+				if !reflect.DeepEqual(found.Branches(), tt.want.branches) {
+					t.Errorf("Branches() = %v, want %v", found.Branches(), tt.want.branches)
+				}
+			}
+		})
+	}
+}
+
+func TestNewParallelStep_RetryPolicy(t *testing.T) {
+	definition := &testDefinition{
+		steps: []saga.Step{
+			saga.NewParallelStep(bookingStep("Hotel"), bookingStep("Car", saga.WithRemoteStepRetry(saga.NewRetryPolicy(3)))),
+			saga.NewRemoteStep().
+				Action(func(context.Context, core.SagaData) msg.DomainCommand { return approveOrder{} }),
+		},
+	}
+
+	_, err := saga.NewValidatedOrchestrator(definition, inmem.NewSagaInstanceStore(), &recordingPublisher{})
+	if !errors.Is(err, saga.ErrBranchRetryPolicy) {
+		t.Errorf("NewValidatedOrchestrator() error = %v, want %v", err, saga.ErrBranchRetryPolicy)
+	}
+}
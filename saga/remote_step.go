@@ -11,6 +11,7 @@ import (
 type RemoteStep struct {
 	actionHandlers map[bool]*remoteStepAction
 	replyHandlers  map[bool]map[string]func(context.Context, core.SagaData, core.Reply) error
+	duplicates     []string
 }
 
 var _ Step = (*RemoteStep)(nil)
@@ -48,6 +49,9 @@ func (s RemoteStep) Action(fn func(context.Context, core.SagaData) msg.DomainCom
 //
 // SuccessReply and FailureReply do not require any special handling unless desired
 func (s RemoteStep) HandleActionReply(reply core.Reply, handler func(context.Context, core.SagaData, core.Reply) error) RemoteStep {
+	if _, exists := s.replyHandlers[notCompensating][reply.ReplyName()]; exists {
+		s.duplicates = append(s.duplicates, reply.ReplyName())
+	}
 	s.replyHandlers[notCompensating][reply.ReplyName()] = handler
 
 	return s
@@ -72,6 +76,9 @@ func (s RemoteStep) Compensation(fn func(context.Context, core.SagaData) msg.Dom
 //
 // SuccessReply does not require any special handling unless desired
 func (s RemoteStep) HandleCompensationReply(reply core.Reply, handler func(context.Context, core.SagaData, core.Reply) error) RemoteStep {
+	if _, exists := s.replyHandlers[isCompensating][reply.ReplyName()]; exists {
+		s.duplicates = append(s.duplicates, reply.ReplyName())
+	}
 	s.replyHandlers[isCompensating][reply.ReplyName()] = handler
 
 	return s
@@ -85,10 +92,20 @@ func (s RemoteStep) getReplyHandler(replyName string, compensating bool) func(co
 	return s.replyHandlers[compensating][replyName]
 }
 
+func (s RemoteStep) getRetryPolicy(compensating bool) *RetryPolicy {
+	if s.actionHandlers[compensating] == nil {
+		return nil
+	}
+
+	return s.actionHandlers[compensating].retryPolicy
+}
+
 func (s RemoteStep) execute(ctx context.Context, sagaData core.SagaData, compensating bool) func(results *stepResults) {
-	if commandToSend := s.actionHandlers[compensating].execute(ctx, sagaData); commandToSend != nil {
+	action := s.actionHandlers[compensating]
+	if commandToSend := action.execute(ctx, sagaData); commandToSend != nil {
 		return func(actions *stepResults) {
 			actions.commands = []msg.DomainCommand{commandToSend}
+			actions.timeout = action.timeout
 		}
 	}
 
@@ -2,14 +2,17 @@ package saga
 
 import (
 	"context"
+	"time"
 
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/msg"
 )
 
 type remoteStepAction struct {
-	predicate func(context.Context, core.SagaData) bool
-	handler   func(context.Context, core.SagaData) msg.DomainCommand
+	predicate   func(context.Context, core.SagaData) bool
+	handler     func(context.Context, core.SagaData) msg.DomainCommand
+	timeout     time.Duration
+	retryPolicy *RetryPolicy
 }
 
 func (a *remoteStepAction) isInvocable(ctx context.Context, sagaData core.SagaData) bool {
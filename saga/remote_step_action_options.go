@@ -2,6 +2,7 @@ package saga
 
 import (
 	"context"
+	"time"
 
 	"github.com/stackus/edat/core"
 )
@@ -15,3 +16,20 @@ func WithRemoteStepPredicate(predicate func(context.Context, core.SagaData) bool
 		step.predicate = predicate
 	}
 }
+
+// WithRemoteStepTimeout sets how long the action may wait for a reply
+//
+// The step fails with a StepTimedOut reply when no reply has been received in time. Compensations cannot fail, and
+// a compensation that times out is reported as an error by the Orchestrator
+func WithRemoteStepTimeout(timeout time.Duration) RemoteStepActionOption {
+	return func(step *remoteStepAction) {
+		step.timeout = timeout
+	}
+}
+
+// WithRemoteStepRetry sets the policy used to send the command again when the step replies with a failure
+func WithRemoteStepRetry(policy *RetryPolicy) RemoteStepActionOption {
+	return func(step *remoteStepAction) {
+		step.retryPolicy = policy
+	}
+}
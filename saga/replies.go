@@ -0,0 +1,15 @@
+package saga
+
+// StepTimedOut is the failure reply used when a remote step has not replied before its deadline
+//
+// Add a handler with RemoteStep.HandleActionReply to run custom code when the step times out
+type StepTimedOut struct{}
+
+// ReplyName implements core.Reply.ReplyName
+func (StepTimedOut) ReplyName() string { return "edat.saga.StepTimedOut" }
+
+// Aborted is the failure reply used for the branches of a ParallelStep that were waiting when the saga was aborted
+type Aborted struct{}
+
+// ReplyName implements core.Reply.ReplyName
+func (Aborted) ReplyName() string { return "edat.saga.Aborted" }
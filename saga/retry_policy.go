@@ -0,0 +1,62 @@
+package saga
+
+import (
+	"time"
+
+	"github.com/stackus/edat/core"
+	"github.com/stackus/edat/retry"
+)
+
+// RetryPolicy decides when a failed remote step should be sent again instead of compensating
+//
+// Retried commands are delayed by the backoff interval. The Orchestrator sends them with msg.WithDeliverAt to a
+// msg.Scheduler, or when scheduled delivery has been disabled with WithOrchestratorScheduledDelivery, holds them in a
+// Clock timer until they are due
+//  saga.NewRemoteStep().
+//    Action(createTicket, saga.WithRemoteStepRetry(saga.NewRetryPolicy(5)))
+type RetryPolicy struct {
+	maxAttempts int
+	backoff     *retry.Backoff
+	retryable   func(replyName string) bool
+}
+
+// NewRetryPolicy constructs a new RetryPolicy that will make at most maxAttempts attempts of a step
+func NewRetryPolicy(maxAttempts int, options ...RetryPolicyOption) *RetryPolicy {
+	p := &RetryPolicy{
+		maxAttempts: maxAttempts,
+		backoff:     retry.NewExponentialBackoff(),
+		retryable:   func(string) bool { return true },
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// RetryReplies returns a predicate for WithRetryPolicyRetryable that only retries the given failure replies
+func RetryReplies(replies ...core.Reply) func(replyName string) bool {
+	names := make(map[string]struct{}, len(replies))
+	for _, reply := range replies {
+		names[reply.ReplyName()] = struct{}{}
+	}
+
+	return func(replyName string) bool {
+		_, exists := names[replyName]
+		return exists
+	}
+}
+
+// shouldRetry returns whether the step should be attempted again after it has already been retried `retries` times
+func (p *RetryPolicy) shouldRetry(replyName string, retries int) bool {
+	return retries+1 < p.maxAttempts && p.retryable(replyName)
+}
+
+func (p *RetryPolicy) interval(retries int) time.Duration {
+	if p.backoff == nil {
+		return 0
+	}
+
+	return p.backoff.Interval(retries + 1)
+}
@@ -0,0 +1,26 @@
+package saga
+
+import (
+	"github.com/stackus/edat/retry"
+)
+
+// RetryPolicyOption options for RetryPolicy
+type RetryPolicyOption func(*RetryPolicy)
+
+// WithRetryPolicyBackoff sets the backoff used to delay each retry
+//
+// A nil backoff will send retries without any delay
+func WithRetryPolicyBackoff(backoff *retry.Backoff) RetryPolicyOption {
+	return func(policy *RetryPolicy) {
+		policy.backoff = backoff
+	}
+}
+
+// WithRetryPolicyRetryable sets the predicate that decides which failure replies are retried
+//
+// All failure replies are retried by default
+func WithRetryPolicyRetryable(retryable func(replyName string) bool) RetryPolicyOption {
+	return func(policy *RetryPolicy) {
+		policy.retryable = retryable
+	}
+}
@@ -0,0 +1,114 @@
+package sagatest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stackus/edat/saga"
+)
+
+// Clock is a deterministic saga.Clock for testing purposes
+//
+// Time only moves forward when Advance is called. Timers that become due are run in the calling goroutine
+// before Advance returns
+type Clock struct {
+	now    time.Time
+	timers []*clockTimer
+	mu     sync.Mutex
+}
+
+type clockTimer struct {
+	clock   *Clock
+	when    time.Time
+	fn      func()
+	stopped bool
+}
+
+var _ saga.Clock = (*Clock)(nil)
+
+// NewClock constructs a new Clock set to now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements saga.Clock.Now
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// AfterFunc implements saga.Clock.AfterFunc
+func (c *Clock) AfterFunc(d time.Duration, fn func()) saga.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &clockTimer{clock: c, when: c.now.Add(d), fn: fn}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Advance moves the clock forward by d and runs the timers that have become due in the order they are due
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var due, pending []*clockTimer
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		due = append(due, t)
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].when.Before(due[j].when)
+	})
+
+	for _, t := range due {
+		t.fn()
+	}
+}
+
+// Pending returns the number of timers that have not been run or stopped
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Stop implements saga.Timer.Stop
+func (t *clockTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	if t.stopped {
+		return false
+	}
+
+	for _, pending := range t.clock.timers {
+		if pending == t {
+			t.stopped = true
+			return true
+		}
+	}
+
+	return false
+}
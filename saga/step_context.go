@@ -4,6 +4,8 @@ type stepContext struct {
 	step         int
 	compensating bool
 	ended        bool
+	retries      int
+	branches     map[int]BranchStatus
 }
 
 func (s *stepContext) next(stepIndex int) stepContext {
@@ -18,6 +20,10 @@ func (s *stepContext) compensate() stepContext {
 	return stepContext{step: s.step, compensating: true, ended: s.ended}
 }
 
+func (s *stepContext) retry() stepContext {
+	return stepContext{step: s.step, compensating: s.compensating, ended: s.ended, retries: s.retries + 1}
+}
+
 func (s *stepContext) end() stepContext {
 	return stepContext{step: s.step, compensating: s.compensating, ended: true}
 }
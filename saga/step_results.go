@@ -1,14 +1,21 @@
 package saga
 
 import (
+	"time"
+
 	"github.com/stackus/edat/core"
 	"github.com/stackus/edat/msg"
 )
 
 type stepResults struct {
 	commands           []msg.DomainCommand
+	commandBranches    []int
+	correlationKey     string
+	timeout            time.Duration
+	delay              time.Duration
 	updatedSagaData    core.SagaData
 	updatedStepContext stepContext
 	local              bool
+	waiting            bool
 	failure            error
 }